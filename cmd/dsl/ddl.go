@@ -0,0 +1,42 @@
+package dsl
+
+import (
+	"fmt"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/importer"
+	"github.com/spf13/cobra"
+)
+
+var CmdFromDDL = &cobra.Command{
+	Use:   "from-ddl [file.sql ...]",
+	Short: "Create the DSL from MySQL CREATE TABLE statements",
+	Long: `
+Read MySQL CREATE TABLE statements from the given files ("-" for stdin) and print the DSL.
+Other statements are ignored. Every table becomes a struct annotated with @table(name=...),
+columns keep their type, NOT NULL, AUTO_INCREMENT, DEFAULT and PRIMARY KEY in the gorm tag,
+primary keys get ego:"primary_key", column comments become trailing field comments.
+
+    $ mysqldump --no-data mydb > schema.sql
+    $ egoctl dsl from-ddl schema.sql -o dsl.go
+`,
+	Args:          cobra.MinimumNArgs(1),
+	RunE:          runFromDDL,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	CmdDSL.AddCommand(CmdFromDDL)
+}
+
+func runFromDDL(c *cobra.Command, args []string) error {
+	ddl, err := readInputs(args)
+	if err != nil {
+		return err
+	}
+	content, err := importer.DDLToDSL(ddl)
+	if err != nil {
+		return fmt.Errorf("解析DDL失败: %w", err)
+	}
+	return writeDSL(content)
+}
@@ -0,0 +1,55 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/gotomicro/egoctl/cmd"
+	"github.com/spf13/cobra"
+)
+
+var CmdDSL = &cobra.Command{
+	Use:   "dsl",
+	Short: "Create the DSL from existing schemas",
+}
+
+var flagOutput string
+
+func init() {
+	CmdDSL.PersistentFlags().StringVarP(&flagOutput, "out", "o", "", "Output DSL file, print to stdout if empty.")
+	cmd.RootCommand.AddCommand(CmdDSL)
+}
+
+// readInputs 读取所有输入文件，"-"表示标准输入
+func readInputs(files []string) (string, error) {
+	contents := make([]string, 0, len(files))
+	for _, file := range files {
+		var content []byte
+		var err error
+		if file == "-" {
+			content, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			content, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			return "", fmt.Errorf("读取文件失败, err: %w", err)
+		}
+		contents = append(contents, string(content))
+	}
+	return strings.Join(contents, "\n"), nil
+}
+
+// writeDSL 将DSL写入--out指定的文件或标准输出
+func writeDSL(content string) error {
+	if flagOutput == "" {
+		fmt.Print(content)
+		return nil
+	}
+	err := ioutil.WriteFile(flagOutput, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("写入DSL文件失败, err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/exporter"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/spf13/cobra"
+)
+
+var CmdExport = &cobra.Command{
+	Use:   "export dsl.go",
+	Short: "Export the DSL models as JSON Schema or OpenAPI components",
+	Long: `
+Export the DSL models. Property names come from json tags, binding:"required" fields are required,
+comments become descriptions.
+
+    $ egoctl dsl export dsl.go --format openapi -o components.json
+    $ egoctl dsl export dsl.go --format jsonschema -o schemas/
+
+With --format jsonschema every model is written to its own file, e.g. schemas/User.schema.json,
+--out is a directory in this case.
+`,
+	Args:          cobra.ExactArgs(1),
+	RunE:          runExport,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var flagExportFormat string
+
+func init() {
+	CmdExport.Flags().StringVar(&flagExportFormat, "format", exporter.FormatOpenAPI, "Export format: openapi or jsonschema.")
+	CmdDSL.AddCommand(CmdExport)
+}
+
+func runExport(c *cobra.Command, args []string) error {
+	content, err := readInputs(args)
+	if err != nil {
+		return err
+	}
+	models, err := parser.ParseDSL(content)
+	if err != nil {
+		return fmt.Errorf("解析DSL失败: %w", err)
+	}
+	if flagExportFormat == exporter.FormatJSONSchema && flagOutput != "" {
+		schemas, err := exporter.ToJSONSchemas(models)
+		if err != nil {
+			return fmt.Errorf("导出失败: %w", err)
+		}
+		err = os.MkdirAll(flagOutput, 0755)
+		if err != nil {
+			return fmt.Errorf("创建目录失败, err: %w", err)
+		}
+		for _, schema := range schemas {
+			if err = writeJSON(filepath.Join(flagOutput, schema.Name), schema.Schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	output, err := exporter.Export(models, flagExportFormat)
+	if err != nil {
+		return fmt.Errorf("导出失败: %w", err)
+	}
+	return writeJSON(flagOutput, output)
+}
+
+// writeJSON 写入格式化的JSON，filename为空时输出到标准输出
+func writeJSON(filename string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码JSON失败, err: %w", err)
+	}
+	content = append(content, '\n')
+	if filename == "" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	err = ioutil.WriteFile(filename, content, 0644)
+	if err != nil {
+		return fmt.Errorf("写入文件失败, err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package dsl
+
+import (
+	"fmt"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/importer"
+	"github.com/spf13/cobra"
+)
+
+var CmdFromOpenAPI = &cobra.Command{
+	Use:   "from-openapi file",
+	Short: "Create the DSL from an OpenAPI 3 or Swagger 2 document",
+	Long: `
+Read an OpenAPI 3 or Swagger 2 document in JSON or YAML ("-" for stdin) and print the DSL.
+Every object in components.schemas (definitions for Swagger 2) becomes a struct, inline objects
+become structs named after the parent and the property. required and enum become the binding tag,
+format becomes the format tag, descriptions become comments.
+
+    $ egoctl dsl from-openapi api.yaml -o dsl.go
+`,
+	Args:          cobra.ExactArgs(1),
+	RunE:          runFromOpenAPI,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	CmdDSL.AddCommand(CmdFromOpenAPI)
+}
+
+func runFromOpenAPI(c *cobra.Command, args []string) error {
+	doc, err := readInputs(args)
+	if err != nil {
+		return err
+	}
+	content, err := importer.OpenAPIToDSL([]byte(doc))
+	if err != nil {
+		return fmt.Errorf("解析OpenAPI文档失败: %w", err)
+	}
+	return writeDSL(content)
+}
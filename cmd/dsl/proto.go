@@ -0,0 +1,46 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/importer"
+	"github.com/spf13/cobra"
+)
+
+var CmdFromProto = &cobra.Command{
+	Use:   "from-proto file.proto [more.proto ...]",
+	Short: "Create the DSL from protobuf messages",
+	Long: `
+Parse .proto files locally, without protoc, and print the DSL. Every message becomes a struct,
+nested messages are named after their parent, e.g. User.Address becomes UserAddress.
+repeated becomes []T, map<K, V> becomes map[K]V, google.protobuf.Timestamp becomes time.Time,
+enums become int32. Pass every file that defines a referenced message.
+
+    $ egoctl dsl from-proto api/user/v1/user.proto api/order/v1/order.proto -o dsl.go
+`,
+	Args:          cobra.MinimumNArgs(1),
+	RunE:          runFromProto,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	CmdDSL.AddCommand(CmdFromProto)
+}
+
+func runFromProto(c *cobra.Command, args []string) error {
+	contents := make([]string, 0, len(args))
+	for _, file := range args {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("读取文件失败, err: %w", err)
+		}
+		contents = append(contents, string(content))
+	}
+	content, err := importer.ProtoToDSL(contents...)
+	if err != nil {
+		return fmt.Errorf("解析proto失败: %w", err)
+	}
+	return writeDSL(content)
+}
@@ -0,0 +1,72 @@
+package dsl
+
+import (
+	"fmt"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web"
+	"github.com/gotomicro/egoctl/internal/app/module/web/importer"
+	"github.com/gotomicro/egoctl/internal/app/module/web/project"
+	"github.com/spf13/cobra"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var CmdPull = &cobra.Command{
+	Use:   "pull",
+	Short: "Create the DSL from the tables of a live database",
+	Long: `
+Connect to a database, read its tables, columns, indexes and comments, and print the DSL.
+
+    $ egoctl dsl pull --dsn "root:@tcp(127.0.0.1:3306)/test" --tables "user*,order" -o dsl.go
+    $ egoctl dsl pull --driver postgres --dsn "postgres://root@127.0.0.1/test?sslmode=disable" --schema public
+    $ egoctl dsl pull --driver sqlite3 --dsn ./test.db --exclude-columns "*.deleted_at"
+
+Use --project to save the DSL into a project of the web UI instead of printing it,
+the web UI must be stopped because its data directory is locked while running.
+`,
+	RunE:          runPull,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	flagPullOption importer.PullOption
+	flagProject    string
+)
+
+func init() {
+	CmdPull.Flags().StringVar(&flagPullOption.Driver, "driver", importer.DriverMySQL, "Database driver: mysql, postgres or sqlite3.")
+	CmdPull.Flags().StringVar(&flagPullOption.DSN, "dsn", "", "Data source name, a go-sql-driver DSN for mysql, a file path for sqlite3.")
+	CmdPull.Flags().StringVar(&flagPullOption.Schema, "schema", "", "Postgres schema, default public.")
+	CmdPull.Flags().StringSliceVar(&flagPullOption.Filter.Tables, "tables", nil, "Only pull the tables matching these patterns.")
+	CmdPull.Flags().StringSliceVar(&flagPullOption.Filter.ExcludeTables, "exclude-tables", nil, "Skip the tables matching these patterns.")
+	CmdPull.Flags().StringSliceVar(&flagPullOption.Filter.ExcludeColumns, "exclude-columns", nil, "Skip the columns matching these patterns, column or table.column.")
+	CmdPull.Flags().StringVar(&flagProject, "project", "", "Save the DSL into the web UI project with exactly this path.")
+	_ = CmdPull.MarkFlagRequired("dsn")
+	CmdDSL.AddCommand(CmdPull)
+}
+
+func runPull(c *cobra.Command, args []string) error {
+	content, err := importer.PullDSL(flagPullOption)
+	if err != nil {
+		return fmt.Errorf("读取数据库表结构失败: %w", err)
+	}
+	if flagProject == "" {
+		return writeDSL(content)
+	}
+	return saveProjectDSL(flagProject, content)
+}
+
+// saveProjectDSL 保存为web界面中项目的DSL，path需要与项目中保存的路径一致
+func saveProjectDSL(path string, content string) error {
+	db, err := leveldb.OpenFile(web.DefaultWebContainer.DataPath, nil)
+	if err != nil {
+		return fmt.Errorf("打开数据目录失败，请先停止egoctl web, err: %w", err)
+	}
+	defer db.Close()
+	project.InitProjectSrv(db)
+	err = project.Srv.ProjectDSL(project.InfoDSL{Path: path, DSL: content})
+	if err != nil {
+		return fmt.Errorf("保存项目DSL失败: %w", err)
+	}
+	return nil
+}
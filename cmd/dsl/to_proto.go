@@ -0,0 +1,65 @@
+package dsl
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/exporter"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/spf13/cobra"
+)
+
+var CmdToProto = &cobra.Command{
+	Use:   "to-proto dsl.go",
+	Short: "Create a .proto file from the DSL",
+	Long: `
+Create a .proto file with one message per DSL model, ready for protoc-gen-go-grpc and the ego plugins.
+Field numbers are saved in .egoctl/proto.json, regenerating never renumbers existing fields,
+removed fields are kept as reserved. Use --service to add a CRUD service for every model.
+
+    $ egoctl dsl to-proto dsl.go --package user.v1 --go-package example.com/api/user/v1 --service -o api/user/v1/user.proto
+`,
+	Args:          cobra.ExactArgs(1),
+	RunE:          runToProto,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	flagProtoOption  exporter.ProtoOption
+	flagProtoNumbers string
+)
+
+func init() {
+	CmdToProto.Flags().StringVar(&flagProtoOption.Package, "package", "egoctl", "Proto package.")
+	CmdToProto.Flags().StringVar(&flagProtoOption.GoPackage, "go-package", "", "Proto option go_package.")
+	CmdToProto.Flags().BoolVar(&flagProtoOption.Service, "service", false, "Add a CRUD service for every model.")
+	CmdToProto.Flags().StringVar(&flagProtoNumbers, "numbers", exporter.DefaultProtoNumbersPath("."), "File saving the field numbers, commit it with the project.")
+	CmdDSL.AddCommand(CmdToProto)
+}
+
+func runToProto(c *cobra.Command, args []string) error {
+	content, err := readInputs(args)
+	if err != nil {
+		return err
+	}
+	models, err := parser.ParseDSL(content)
+	if err != nil {
+		return fmt.Errorf("解析DSL失败: %w", err)
+	}
+	numbers, err := exporter.ReadProtoNumbers(flagProtoNumbers)
+	if err != nil {
+		return err
+	}
+	proto, err := exporter.ToProto(models, flagProtoOption, numbers)
+	if err != nil {
+		return fmt.Errorf("生成proto失败: %w", err)
+	}
+	// 先写proto再保存编号，生成失败时不会记录未使用的编号
+	if flagOutput == "" {
+		fmt.Print(proto)
+	} else if err = ioutil.WriteFile(flagOutput, []byte(proto), 0644); err != nil {
+		return fmt.Errorf("写入proto文件失败, err: %w", err)
+	}
+	return exporter.WriteProtoNumbers(flagProtoNumbers, numbers)
+}
@@ -0,0 +1,270 @@
+package gen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotomicro/egoctl/cmd"
+	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/gotomicro/egoctl/internal/app/module/web/template"
+	"github.com/gotomicro/egoctl/internal/git"
+	"github.com/gotomicro/egoctl/internal/system"
+	"github.com/gotomicro/egoctl/internal/utils"
+	"github.com/pelletier/go-toml"
+	"github.com/spf13/cobra"
+)
+
+var CmdGen = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate code from the DSL without the web UI",
+	Long: `
+Generate code from a DSL file, the same way the web UI does, and exit non-zero on failure.
+
+Options are read from the project config file (default ./egoctl.toml), flags override it:
+
+    dsl           = "./dsl.go"
+    gitRemotePath = "https://github.com/gotomicro/egoctl-tmpls.git"
+    tmplPath      = ""       # use a local template directory instead of gitRemotePath
+    templateRef   = "v1.2.0" # tag, branch or commit of the template, the synced HEAD if empty
+    proType       = "ego-gin"
+    apiPrefix     = "/api"
+    language      = "Go"
+    projectPath   = "."
+    enableFormat  = false
+    enableModule  = ["upload"]   # only generate these template modules and their dependencies, all if empty
+
+    [variables]                  # values of the variables declared by the template
+    port = 9001
+
+Use --dry-run to print the status and unified diff of every file without touching the disk.
+Use --prune to delete files that are no longer generated and have not been edited since.
+`,
+	RunE:          runGen,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// Option 项目配置文件和命令行参数
+type Option struct {
+	DSL           string                 `toml:"dsl"`
+	GitRemotePath string                 `toml:"gitRemotePath"`
+	TmplPath      string                 `toml:"tmplPath"`
+	TemplateRef   string                 `toml:"templateRef"` // 模板版本，为空时使用模板当前的代码
+	ProType       string                 `toml:"proType"`
+	ApiPrefix     string                 `toml:"apiPrefix"`
+	Language      string                 `toml:"language"`
+	ProjectPath   string                 `toml:"projectPath"`
+	EnableFormat  bool                   `toml:"enableFormat"`
+	EnableModule  []string               `toml:"enableModule"` // 开启的模块，没有配置时生成所有模块，空列表不生成任何模块
+	Variables     map[string]interface{} `toml:"variables"`    // 模板变量
+}
+
+var (
+	flagConfig string
+	flagDryRun bool
+	flagPrune  bool
+	flagOption Option
+	flagVars   map[string]string
+)
+
+func init() {
+	CmdGen.PersistentFlags().StringVarP(&flagConfig, "config", "c", "./egoctl.toml", "Project config file, skipped if it does not exist.")
+	CmdGen.PersistentFlags().StringVarP(&flagOption.DSL, "dsl", "d", "", "DSL file path.")
+	CmdGen.PersistentFlags().StringVarP(&flagOption.GitRemotePath, "git", "g", "", "Template git url, cloned into the egoctl home if missing.")
+	CmdGen.PersistentFlags().StringVarP(&flagOption.TmplPath, "tmpl", "t", "", "Local template path, takes precedence over --git.")
+	CmdGen.PersistentFlags().StringVar(&flagOption.TemplateRef, "ref", "", "Template tag, branch or commit to render from, checked out as a worktree.")
+	CmdGen.PersistentFlags().StringVarP(&flagOption.ProType, "pro-type", "p", "", "Template type, the sub directory of the template path.")
+	CmdGen.PersistentFlags().StringVarP(&flagOption.ApiPrefix, "api-prefix", "a", "", "API prefix.")
+	CmdGen.PersistentFlags().StringVarP(&flagOption.Language, "language", "l", "", "Project language: Go, React, Vue.")
+	CmdGen.PersistentFlags().StringVarP(&flagOption.ProjectPath, "out", "o", "", "Project path the code is generated into.")
+	CmdGen.PersistentFlags().BoolVarP(&flagOption.EnableFormat, "format", "f", false, "Format generated go code.")
+	CmdGen.PersistentFlags().StringSliceVarP(&flagOption.EnableModule, "module", "m", nil, "Template modules to generate, with their dependencies, all if not set, none if \"\".")
+	CmdGen.PersistentFlags().StringToStringVar(&flagVars, "var", nil, "Template variables, e.g. --var port=9001,auth=true, merged into the config file.")
+	CmdGen.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Render in memory and print the diff of every file, without writing.")
+	CmdGen.PersistentFlags().BoolVar(&flagPrune, "prune", false, "Delete files of models removed from the DSL, unless edited by hand.")
+	cmd.RootCommand.AddCommand(CmdGen)
+}
+
+func runGen(c *cobra.Command, args []string) error {
+	option, err := loadOption(c)
+	if err != nil {
+		return err
+	}
+	userOption, err := option.ToUserOption()
+	if err != nil {
+		return err
+	}
+	userOption.DryRun = flagDryRun
+	userOption.Prune = flagPrune
+	parserObj := parser.NewParser(userOption)
+	err = parserObj.Run()
+	if err != nil {
+		return fmt.Errorf("生成代码失败: %w", err)
+	}
+	if flagDryRun {
+		printChanges(parserObj.GetChanges())
+		return nil
+	}
+	return checkConflicts(parserObj.GetChanges())
+}
+
+// checkConflicts 输出需要关注的文件，存在合并冲突时返回错误
+func checkConflicts(changes []parser.FileChange) error {
+	conflicts := 0
+	for _, change := range changes {
+		switch change.Status {
+		case parser.FileStatusConflict:
+			fmt.Printf("%-10s %s (%d)\n", change.Status, change.Path, change.Conflicts)
+			if len(change.DroppedRegions) > 0 {
+				fmt.Printf("%-10s 新模板中没有手写代码区域: %s，文件没有覆盖\n", "", strings.Join(change.DroppedRegions, ", "))
+			}
+			conflicts++
+		case parser.FileStatusObsolete, parser.FileStatusOrphaned, parser.FileStatusRemoved:
+			fmt.Printf("%-10s %s\n", change.Status, change.Path)
+		}
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("%d个文件存在合并冲突，请手动解决", conflicts)
+	}
+	return nil
+}
+
+// printChanges 输出每个文件的状态，以及有变化文件的diff
+func printChanges(changes []parser.FileChange) {
+	for _, change := range changes {
+		fmt.Printf("%-10s %s\n", change.Status, change.Path)
+	}
+	for _, change := range changes {
+		if change.Diff != "" {
+			fmt.Printf("\n%s", change.Diff)
+		}
+	}
+}
+
+// loadOption 读取项目配置文件，再用命令行中显式设置的参数覆盖
+func loadOption(c *cobra.Command) (option Option, err error) {
+	if utils.IsExist(flagConfig) {
+		tree, err := toml.LoadFile(flagConfig)
+		if err != nil {
+			return option, fmt.Errorf("读取配置文件失败, err: %w", err)
+		}
+		err = tree.Unmarshal(&option)
+		if err != nil {
+			return option, fmt.Errorf("解析配置文件失败, err: %w", err)
+		}
+	}
+
+	flags := c.Flags()
+	if flags.Changed("dsl") {
+		option.DSL = flagOption.DSL
+	}
+	if flags.Changed("git") {
+		option.GitRemotePath = flagOption.GitRemotePath
+	}
+	if flags.Changed("tmpl") {
+		option.TmplPath = flagOption.TmplPath
+	}
+	if flags.Changed("ref") {
+		option.TemplateRef = flagOption.TemplateRef
+	}
+	if flags.Changed("pro-type") {
+		option.ProType = flagOption.ProType
+	}
+	if flags.Changed("api-prefix") {
+		option.ApiPrefix = flagOption.ApiPrefix
+	}
+	if flags.Changed("language") {
+		option.Language = flagOption.Language
+	}
+	if flags.Changed("out") {
+		option.ProjectPath = flagOption.ProjectPath
+	}
+	if flags.Changed("format") {
+		option.EnableFormat = flagOption.EnableFormat
+	}
+	if flags.Changed("module") {
+		option.EnableModule = flagOption.EnableModule
+	}
+	if len(flagVars) > 0 && option.Variables == nil {
+		option.Variables = make(map[string]interface{})
+	}
+	for key, value := range flagVars {
+		option.Variables[key] = value
+	}
+
+	if option.Language == "" {
+		option.Language = constx.LanguageGo
+	}
+	if option.ProjectPath == "" {
+		option.ProjectPath = "."
+	}
+	return option, nil
+}
+
+// ToUserOption 转换为解析器使用的用户配置
+func (o Option) ToUserOption() (parser.UserOption, error) {
+	if o.DSL == "" {
+		return parser.UserOption{}, fmt.Errorf("dsl不能为空")
+	}
+	if o.ProType == "" {
+		return parser.UserOption{}, fmt.Errorf("proType不能为空")
+	}
+	dslContent, err := ioutil.ReadFile(o.DSL)
+	if err != nil {
+		return parser.UserOption{}, fmt.Errorf("读取dsl文件失败, err: %w", err)
+	}
+	tmplPath, err := o.templatePath()
+	if err != nil {
+		return parser.UserOption{}, err
+	}
+	projectPath, err := filepath.Abs(o.ProjectPath)
+	if err != nil {
+		return parser.UserOption{}, fmt.Errorf("获取项目路径失败, err: %w", err)
+	}
+	return parser.UserOption{
+		Language:           o.Language,
+		ScaffoldDSLContent: string(dslContent),
+		ProType:            o.ProType,
+		ApiPrefix:          o.ApiPrefix,
+		EnableModule:       o.EnableModule,
+		Variables:          o.Variables,
+		ProjectPath:        projectPath,
+		GitLocalPath:       tmplPath,
+		EnableFormat:       o.EnableFormat,
+		Path: map[string]string{
+			"backend": ".",
+		},
+	}, nil
+}
+
+// templatePath 获取模板本地路径，git地址的模板不存在时会先clone，设置了templateRef时使用该版本的worktree
+func (o Option) templatePath() (string, error) {
+	if o.TmplPath != "" {
+		if !utils.IsDir(o.TmplPath) {
+			return "", fmt.Errorf("模板目录不存在: %s", o.TmplPath)
+		}
+		tmplPath, err := filepath.Abs(o.TmplPath)
+		if err != nil {
+			return "", fmt.Errorf("获取模板路径失败, err: %w", err)
+		}
+		return template.RevisionPath(tmplPath, o.TemplateRef)
+	}
+	if o.GitRemotePath == "" {
+		return "", fmt.Errorf("tmplPath和gitRemotePath不能同时为空")
+	}
+	urlInfo, err := template.GitURL(o.GitRemotePath).Parse()
+	if err != nil {
+		return "", fmt.Errorf("URL解析失败, err: %w", err)
+	}
+	localPath := system.EgoctlHome + "/egoctl/git" + urlInfo.Path
+	if !utils.IsDir(localPath) {
+		err = git.CloneRepo(o.GitRemotePath, localPath)
+		if err != nil {
+			return "", fmt.Errorf("下载模板失败, err: %w", err)
+		}
+	}
+	return template.RevisionPath(localPath, o.TemplateRef)
+}
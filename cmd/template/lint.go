@@ -0,0 +1,57 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/spf13/cobra"
+)
+
+var CmdLint = &cobra.Command{
+	Use:   "lint [dir]",
+	Short: "Validate a template repository",
+	Long: `
+Validate the egoctl.toml and templates of a template repository without generating a project.
+dir is a template type directory containing egoctl.toml, or a repository whose sub directories
+contain egoctl.toml, the current directory by default.
+
+    $ egoctl template lint ./egoctl-tmpls
+    egoctl-tmpls/ego/egoctl.toml:12:1: descriptor model.go: 模板文件ego/model.go不存在
+    egoctl-tmpls/ego/ego/api.go:8:15: Filter 'snake' does not exist.
+
+The command exits with a non-zero code when any problem is found.
+`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runLint,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	CmdTemplate.AddCommand(CmdLint)
+}
+
+func runLint(c *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dirs, err := proTypeDirs(dir)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, proTypeDir := range dirs {
+		for _, problem := range parser.LintTemplate(proTypeDir) {
+			problem.File = filepath.Join(proTypeDir, problem.File)
+			fmt.Println(problem.String())
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("模板检查发现%d个问题", count)
+	}
+	fmt.Printf("检查了%d个模板类型，没有发现问题\n", len(dirs))
+	return nil
+}
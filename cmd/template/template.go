@@ -0,0 +1,42 @@
+package template
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/gotomicro/egoctl/cmd"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/gotomicro/egoctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var CmdTemplate = &cobra.Command{
+	Use:   "template",
+	Short: "Tools for template repositories",
+}
+
+func init() {
+	cmd.RootCommand.AddCommand(CmdTemplate)
+}
+
+// proTypeDirs 模板类型目录，dir下有egoctl.toml时为dir本身，否则为dir下所有包含egoctl.toml的子目录
+func proTypeDirs(dir string) ([]string, error) {
+	if utils.IsExist(filepath.Join(dir, parser.TmplConfigFile)) {
+		return []string{dir}, nil
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取模板目录失败, err: %w", err)
+	}
+	dirs := make([]string, 0)
+	for _, file := range files {
+		if file.IsDir() && utils.IsExist(filepath.Join(dir, file.Name(), parser.TmplConfigFile)) {
+			dirs = append(dirs, filepath.Join(dir, file.Name()))
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("%s下没有找到%s", dir, parser.TmplConfigFile)
+	}
+	return dirs, nil
+}
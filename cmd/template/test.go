@@ -0,0 +1,85 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/spf13/cobra"
+)
+
+var CmdTest = &cobra.Command{
+	Use:   "test [dir]",
+	Short: "Run the golden file tests of a template repository",
+	Long: `
+Generate every test case of a template into a temporary directory and compare it with the golden files.
+dir is a template type directory containing egoctl.toml, or a repository whose sub directories
+contain egoctl.toml, the current directory by default.
+
+A test case is a directory under <template type>/testdata:
+
+    ego-gin/testdata/user/dsl.go       # the DSL
+    ego-gin/testdata/user/case.toml    # optional: language, apiPrefix, enableModule, enableFormat, [variables]
+    ego-gin/testdata/user/go.mod       # optional, a go.mod named after the case is used for Go projects
+    ego-gin/testdata/user/golden/      # the expected project
+
+    $ egoctl template test ./egoctl-tmpls
+    $ egoctl template test ./egoctl-tmpls/ego-gin --update
+
+Use --update to rewrite the golden files with the generated result after reviewing the diff.
+`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runTest,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var flagUpdate bool
+
+func init() {
+	CmdTest.Flags().BoolVar(&flagUpdate, "update", false, "Rewrite the golden files with the generated result.")
+	CmdTemplate.AddCommand(CmdTest)
+}
+
+func runTest(c *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dirs, err := proTypeDirs(dir)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, proTypeDir := range dirs {
+		results, err := parser.RunTemplateTests(proTypeDir, flagUpdate)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			name := filepath.Join(proTypeDir, parser.TemplateTestDir, result.Name)
+			switch {
+			case result.Err != nil:
+				fmt.Printf("FAIL    %s: %s\n", name, result.Err)
+				failed++
+			case result.Updated:
+				fmt.Printf("UPDATE  %s\n", name)
+			case result.Passed():
+				fmt.Printf("ok      %s\n", name)
+			default:
+				fmt.Printf("FAIL    %s\n", name)
+				for _, d := range result.Diffs {
+					fmt.Printf("        %-8s %s\n", d.Status, d.Path)
+				}
+				for _, d := range result.Diffs {
+					fmt.Printf("\n%s", d.Diff)
+				}
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d个测试用例失败，确认生成结果正确后可以使用--update更新golden", failed)
+	}
+	return nil
+}
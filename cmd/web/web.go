@@ -26,6 +26,8 @@ func init() {
 		},
 	}
 	CmdGenerate.PersistentFlags().StringVarP(&flagConfig, "start", "s", "./egoctl.toml", "")
+	// web不读取该配置，保留参数兼容已有的脚本
+	_ = CmdGenerate.PersistentFlags().MarkDeprecated("start", "egoctl web does not read it, use egoctl gen --config instead")
 	CmdGenerate.AddCommand(codeCmd)
 	cmd.RootCommand.AddCommand(CmdGenerate)
 }
@@ -1,8 +1,12 @@
 package web
 
 import (
+	"errors"
+
 	"github.com/gotomicro/ego/server/egin"
 	"github.com/gotomicro/egoctl/internal/app/module/web/core"
+	"github.com/gotomicro/egoctl/internal/app/module/web/importer"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
 	"github.com/gotomicro/egoctl/internal/app/module/web/project"
 	"github.com/gotomicro/egoctl/internal/app/module/web/template"
 	"github.com/gotomicro/gotoant"
@@ -10,17 +14,30 @@ import (
 
 func (c *Container) API(component *egin.Component) {
 	component.GET("/api/projects", core.Handle(c.apiProjectList))
-	component.GET("/api/projects/gen", core.Handle(c.apiProjectGen))       // 生成代码
-	component.GET("/api/projects/render", core.Handle(c.apiProjectRender)) // 生成代码
+	component.GET("/api/projects/gen", core.Handle(c.apiProjectGen))         // 生成代码
+	component.GET("/api/projects/render", core.Handle(c.apiProjectRender))   // 生成代码
+	component.GET("/api/projects/preview", core.Handle(c.apiProjectPreview)) // 预览生成代码的文件变更
+	component.GET("/api/projects/export", core.Handle(c.apiProjectExport))   // 导出DSL模型为JSON Schema、OpenAPI
 	component.POST("/api/projects", core.Handle(c.apiProjectCreate))
 	component.PUT("/api/projects", core.Handle(c.apiProjectUpdate))
 	component.PUT("/api/projects/dsl", core.Handle(c.apiProjectDSL))
+	component.GET("/api/projects/variables", core.Handle(c.apiProjectVariables))       // 模板变量
+	component.PUT("/api/projects/variables", core.Handle(c.apiProjectUpdateVariables)) // 保存模板变量
+	component.PUT("/api/projects/template-ref", core.Handle(c.apiProjectTemplateRef))  // 切换模板版本
+	component.GET("/api/projects/upgrade", core.Handle(c.apiProjectUpgradePreview))    // 预览升级模板版本后生成代码的差异
+	component.PUT("/api/projects/templates", core.Handle(c.apiProjectTemplates))       // 保存主模板之外绑定的模板
 	component.DELETE("/api/projects", core.Handle(c.apiProjectDelete))
+	component.POST("/api/dsl/ddl", core.Handle(c.apiDSLFromDDL))         // 从CREATE TABLE语句生成DSL
+	component.POST("/api/dsl/openapi", core.Handle(c.apiDSLFromOpenAPI)) // 从OpenAPI文档生成DSL
 	component.GET("/api/templates", core.Handle(c.apiTemplateList))
 	component.GET("/api/templates/select", core.Handle(c.apiTemplateSelect))
+	component.GET("/api/templates/modules", core.Handle(c.apiTemplateModules)) // 模板声明的模块
+	component.GET("/api/templates/tags", core.Handle(c.apiTemplateTags))       // 模板的tag列表
 	component.POST("/api/templates", core.Handle(c.apiTemplateCreate))
 	component.PUT("/api/templates", core.Handle(c.apiTemplateUpdate))
-	component.PUT("/api/templates/sync", core.Handle(c.apiTemplateSync)) // 同步模板代码
+	component.PUT("/api/templates/sync", core.Handle(c.apiTemplateSync))   // 同步模板代码
+	component.PUT("/api/templates/fetch", core.Handle(c.apiTemplateFetch)) // 获取模板的更新，返回提交和变化的文件
+	component.PUT("/api/templates/apply", core.Handle(c.apiTemplateApply)) // 更新模板到预览时的版本
 	component.DELETE("/api/templates", core.Handle(c.apiTemplateDelete))
 }
 
@@ -34,18 +51,22 @@ func (c *Container) apiProjectList(ctx *core.Context) {
 }
 
 func (c *Container) apiProjectGen(ctx *core.Context) {
-	req := project.InfoUniqId{}
+	req := project.InfoGen{}
 	err := ctx.Bind(&req)
 	if err != nil {
 		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
 		return
 	}
-	err = project.Srv.ProjectGen(req)
+	list, err := project.Srv.ProjectGen(req)
 	if err != nil {
-		ctx.JSONE(1, "生成代码失败: err"+err.Error(), make([]struct{}, 0))
+		if list == nil {
+			list = make([]parser.FileChange, 0)
+		}
+		// 返回出错前已经得到的生成结果
+		ctx.JSONE(1, "生成代码失败: err"+err.Error(), errData(err, list))
 		return
 	}
-	ctx.JSONOK()
+	ctx.JSONOK(list)
 }
 
 // 获取项目渲染数据
@@ -58,12 +79,47 @@ func (c *Container) apiProjectRender(ctx *core.Context) {
 	}
 	info, err := project.Srv.ProjectRender(req)
 	if err != nil {
-		ctx.JSONE(1, "获取数据失败: err"+err.Error(), err)
+		ctx.JSONE(1, "获取数据失败: err"+err.Error(), errData(err, err))
 		return
 	}
 	ctx.JSONOK(info)
 }
 
+// 预览生成代码，不写入磁盘
+func (c *Container) apiProjectPreview(ctx *core.Context) {
+	req := project.InfoUniqId{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	list, err := project.Srv.ProjectPreview(req)
+	if err != nil {
+		if list == nil {
+			list = make([]parser.FileChange, 0)
+		}
+		ctx.JSONE(1, "预览代码失败: err"+err.Error(), errData(err, list))
+		return
+	}
+	ctx.JSONOK(list)
+}
+
+// 导出DSL模型为JSON Schema或OpenAPI的components.schemas
+func (c *Container) apiProjectExport(ctx *core.Context) {
+	req := project.InfoExport{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	resp, err := project.Srv.ProjectExport(req)
+	if err != nil {
+		ctx.JSONE(1, "导出失败: err"+err.Error(), errData(err, err))
+		return
+	}
+	ctx.JSONOK(resp)
+}
+
 func (c *Container) apiProjectCreate(ctx *core.Context) {
 	req := project.Info{}
 	err := ctx.Bind(&req)
@@ -103,7 +159,87 @@ func (c *Container) apiProjectDSL(ctx *core.Context) {
 	}
 	err = project.Srv.ProjectDSL(req)
 	if err != nil {
-		ctx.JSONE(1, "更新项目失败: err"+err.Error(), err)
+		ctx.JSONE(1, "更新项目失败: err"+err.Error(), errData(err, err))
+		return
+	}
+	ctx.JSONOK()
+}
+
+// 获取模板声明的变量和项目中填写的值
+func (c *Container) apiProjectVariables(ctx *core.Context) {
+	req := project.InfoBinding{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	resp, err := project.Srv.ProjectVariables(req)
+	if err != nil {
+		ctx.JSONE(1, "获取模板变量失败: err"+err.Error(), err)
+		return
+	}
+	ctx.JSONOK(resp)
+}
+
+// 保存项目的模板变量
+func (c *Container) apiProjectUpdateVariables(ctx *core.Context) {
+	req := project.InfoVariables{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	err = project.Srv.ProjectUpdateVariables(req)
+	if err != nil {
+		ctx.JSONE(1, "保存模板变量失败: err"+err.Error(), err)
+		return
+	}
+	ctx.JSONOK()
+}
+
+// 切换项目使用的模板版本
+func (c *Container) apiProjectTemplateRef(ctx *core.Context) {
+	req := project.InfoTemplateRef{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	err = project.Srv.ProjectUpdateTemplateRef(req)
+	if err != nil {
+		ctx.JSONE(1, "切换模板版本失败: err"+err.Error(), err)
+		return
+	}
+	ctx.JSONOK()
+}
+
+// 预览切换模板版本对生成代码的影响
+func (c *Container) apiProjectUpgradePreview(ctx *core.Context) {
+	req := project.InfoUpgradePreview{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	resp, err := project.Srv.ProjectUpgradePreview(req)
+	if err != nil {
+		ctx.JSONE(1, "预览模板升级失败: err"+err.Error(), err)
+		return
+	}
+	ctx.JSONOK(resp)
+}
+
+// 保存项目在主模板之外绑定的模板
+func (c *Container) apiProjectTemplates(ctx *core.Context) {
+	req := project.InfoTemplates{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	err = project.Srv.ProjectUpdateTemplates(req)
+	if err != nil {
+		ctx.JSONE(1, "保存绑定的模板失败: err"+err.Error(), err)
 		return
 	}
 	ctx.JSONOK()
@@ -124,6 +260,38 @@ func (c *Container) apiProjectDelete(ctx *core.Context) {
 	ctx.JSONOK()
 }
 
+// 从CREATE TABLE语句生成DSL，由用户确认后再保存到项目
+func (c *Container) apiDSLFromDDL(ctx *core.Context) {
+	req := importer.InfoDDL{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	content, err := importer.DDLToDSL(req.DDL)
+	if err != nil {
+		ctx.JSONE(1, "解析DDL失败: err"+err.Error(), nil)
+		return
+	}
+	ctx.JSONOK(content)
+}
+
+// 从OpenAPI 3或Swagger 2文档生成DSL
+func (c *Container) apiDSLFromOpenAPI(ctx *core.Context) {
+	req := importer.InfoOpenAPI{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	content, err := importer.OpenAPIToDSL([]byte(req.Content))
+	if err != nil {
+		ctx.JSONE(1, "解析OpenAPI文档失败: err"+err.Error(), nil)
+		return
+	}
+	ctx.JSONOK(content)
+}
+
 func (c *Container) apiTemplateList(ctx *core.Context) {
 	list, err := template.Srv.TemplateList()
 	if err != nil {
@@ -147,6 +315,38 @@ func (c *Container) apiTemplateSelect(ctx *core.Context) {
 	ctx.JSONOK(antselect.GetOptions())
 }
 
+// 获取模板每个模板类型声明的模块
+func (c *Container) apiTemplateModules(ctx *core.Context) {
+	req := template.InfoModules{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	list, err := template.Srv.TemplateModules(req)
+	if err != nil {
+		ctx.JSONE(1, "获取模板模块失败: err"+err.Error(), make([]struct{}, 0))
+		return
+	}
+	ctx.JSONOK(list)
+}
+
+// 获取模板的tag列表
+func (c *Container) apiTemplateTags(ctx *core.Context) {
+	req := template.InfoUniqId{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	list, err := template.Srv.TemplateTags(req)
+	if err != nil {
+		ctx.JSONE(1, "获取模板tag失败: err"+err.Error(), make([]struct{}, 0))
+		return
+	}
+	ctx.JSONOK(list)
+}
+
 // 创建模板
 func (c *Container) apiTemplateCreate(ctx *core.Context) {
 	req := template.Info{}
@@ -193,6 +393,38 @@ func (c *Container) apiTemplateSync(ctx *core.Context) {
 	ctx.JSONOK()
 }
 
+// 获取模板远程的更新，用于同步前预览
+func (c *Container) apiTemplateFetch(ctx *core.Context) {
+	req := template.InfoUniqId{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	resp, err := template.Srv.TemplateFetch(req)
+	if err != nil {
+		ctx.JSONE(1, "获取模板更新失败: err"+err.Error(), nil)
+		return
+	}
+	ctx.JSONOK(resp)
+}
+
+// 将模板更新到预览时的远程版本
+func (c *Container) apiTemplateApply(ctx *core.Context) {
+	req := template.InfoApply{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		ctx.JSONE(1, "获取参数失败: err"+err.Error(), err)
+		return
+	}
+	err = template.Srv.TemplateApply(req)
+	if err != nil {
+		ctx.JSONE(1, "更新模板失败: err"+err.Error(), nil)
+		return
+	}
+	ctx.JSONOK()
+}
+
 func (c *Container) apiTemplateDelete(ctx *core.Context) {
 	req := template.InfoUniqId{}
 	err := ctx.Bind(&req)
@@ -209,3 +441,12 @@ func (c *Container) apiTemplateDelete(ctx *core.Context) {
 	}
 	ctx.JSONOK()
 }
+
+// errData 错误中包含DSL诊断信息时返回诊断信息，前端编辑器据此标注出错的行，否则返回data
+func errData(err error, data interface{}) interface{} {
+	var diagnostics parser.Diagnostics
+	if errors.As(err, &diagnostics) {
+		return diagnostics
+	}
+	return data
+}
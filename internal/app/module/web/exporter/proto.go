@@ -0,0 +1,449 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/gotomicro/egoctl/internal/utils"
+)
+
+const ProtoNumbersFile = "proto.json" // 生成proto时记录的字段编号
+
+var protoScalarTypes = map[string]string{
+	"bool":    "bool",
+	"string":  "string",
+	"int":     "int64",
+	"int8":    "int32",
+	"int16":   "int32",
+	"int32":   "int32",
+	"int64":   "int64",
+	"uint":    "uint64",
+	"uint8":   "uint32",
+	"uint16":  "uint32",
+	"uint32":  "uint32",
+	"uint64":  "uint64",
+	"float32": "float",
+	"float64": "double",
+	"byte":    "uint32",
+	"rune":    "int32",
+}
+
+var protoWellKnownTypes = map[string][2]string{
+	"time.Time":   {"google.protobuf.Timestamp", "google/protobuf/timestamp.proto"},
+	"interface{}": {"google.protobuf.Value", "google/protobuf/struct.proto"},
+	"any":         {"google.protobuf.Value", "google/protobuf/struct.proto"},
+}
+
+// ProtoOption 生成proto的参数
+type ProtoOption struct {
+	Package   string // proto的package，默认为egoctl
+	GoPackage string // option go_package，为空时不生成
+	Service   bool   // 是否为每个模型生成CRUD服务
+}
+
+// ProtoNumbers 字段编号，key为模型名和proto字段名。删除的字段不会从中移除，保证编号不会被复用
+type ProtoNumbers struct {
+	Messages map[string]map[string]ProtoNumber `json:"messages"`
+}
+
+// ProtoNumber 字段的编号和proto类型，类型变化时旧编号记录到Retired中保留，字段使用新编号
+type ProtoNumber struct {
+	Number  int    `json:"number"`
+	Type    string `json:"type"`
+	Retired []int  `json:"retired,omitempty"`
+}
+
+// DefaultProtoNumbersPath 项目中字段编号文件的默认路径
+func DefaultProtoNumbersPath(projectPath string) string {
+	return filepath.Join(projectPath, parser.EgoctlDir, ProtoNumbersFile)
+}
+
+// ReadProtoNumbers 读取字段编号，文件不存在时返回空编号
+func ReadProtoNumbers(filename string) (*ProtoNumbers, error) {
+	numbers := &ProtoNumbers{Messages: make(map[string]map[string]ProtoNumber)}
+	if !utils.IsExist(filename) {
+		return numbers, nil
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取字段编号失败, err: %w", err)
+	}
+	err = json.Unmarshal(content, numbers)
+	if err != nil {
+		return nil, fmt.Errorf("解析字段编号失败, err: %w", err)
+	}
+	if numbers.Messages == nil {
+		numbers.Messages = make(map[string]map[string]ProtoNumber)
+	}
+	return numbers, nil
+}
+
+// WriteProtoNumbers 写入字段编号
+func WriteProtoNumbers(filename string, numbers *ProtoNumbers) error {
+	content, err := json.MarshalIndent(numbers, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码字段编号失败, err: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return fmt.Errorf("创建字段编号目录失败, err: %w", err)
+	}
+	err = ioutil.WriteFile(filename, content, 0644)
+	if err != nil {
+		return fmt.Errorf("写入字段编号失败, err: %w", err)
+	}
+	return nil
+}
+
+// number 返回字段编号，新字段或类型变化的字段使用该模型用过的最大编号+1
+func (n *ProtoNumbers) number(message string, field string, typ string) int {
+	fields, ok := n.Messages[message]
+	if !ok {
+		fields = make(map[string]ProtoNumber)
+		n.Messages[message] = fields
+	}
+	current, ok := fields[field]
+	if ok && current.Type == typ {
+		return current.Number
+	}
+	max := 0
+	for _, number := range fields {
+		if number.Number > max {
+			max = number.Number
+		}
+		for _, retired := range number.Retired {
+			if retired > max {
+				max = retired
+			}
+		}
+	}
+	// 19000-19999为protobuf保留编号
+	if max+1 >= 19000 && max+1 <= 19999 {
+		max = 19999
+	}
+	if ok {
+		// 类型不兼容，旧编号保留，避免旧数据按新类型解析
+		current.Retired = append(current.Retired, current.Number)
+	}
+	current.Number = max + 1
+	current.Type = typ
+	fields[field] = current
+	return current.Number
+}
+
+type protoField struct {
+	Name     string
+	Type     string
+	Number   int
+	JSONName string
+	Comment  string
+}
+
+type protoMessage struct {
+	Name     string
+	Docs     []string
+	Fields   []protoField
+	Reserved []protoField
+}
+
+type protoWriter struct {
+	option   ProtoOption
+	numbers  *ProtoNumbers
+	models   map[string]parser.SpecType
+	imports  map[string]bool
+	messages []protoMessage
+}
+
+// ToProto 生成proto文件，每个模型一个message，字段编号从numbers中读取，新字段的编号会写回numbers
+func ToProto(models []parser.SpecType, option ProtoOption, numbers *ProtoNumbers) (string, error) {
+	if option.Package == "" {
+		option.Package = "egoctl"
+	}
+	if numbers.Messages == nil {
+		numbers.Messages = make(map[string]map[string]ProtoNumber)
+	}
+	w := &protoWriter{
+		option:  option,
+		numbers: numbers,
+		models:  make(map[string]parser.SpecType, len(models)),
+		imports: make(map[string]bool),
+	}
+	for _, model := range models {
+		w.models[model.Name] = model
+	}
+	for _, model := range models {
+		message, err := w.message(model)
+		if err != nil {
+			return "", fmt.Errorf("模型%s生成proto失败, err: %w", model.Name, err)
+		}
+		w.messages = append(w.messages, message)
+	}
+
+	services := make([]string, 0)
+	if option.Service {
+		for _, model := range models {
+			service, err := w.service(model)
+			if err != nil {
+				return "", fmt.Errorf("模型%s生成服务失败, err: %w", model.Name, err)
+			}
+			services = append(services, service)
+		}
+	}
+	return w.String(services), nil
+}
+
+func (w *protoWriter) message(model parser.SpecType) (protoMessage, error) {
+	message := protoMessage{Name: model.Name}
+	if description := modelDescription(model); description != "" {
+		message.Docs = strings.Split(description, "\n")
+	}
+
+	names := make(map[string]bool, len(model.Members))
+	for _, member := range model.Members {
+		jsonName := ""
+		for _, tag := range member.Tag.Value {
+			if tag.Name == "json" {
+				jsonName = fieldJSONName("", tag)
+			}
+		}
+		if jsonName == "-" {
+			continue
+		}
+		typ, err := w.fieldType(member.Type)
+		if err != nil {
+			return message, fmt.Errorf("字段%s: %w", member.Name, err)
+		}
+		name := utils.SnakeString(member.Name)
+		if names[name] {
+			return message, fmt.Errorf("字段%s对应的proto字段%s重复", member.Name, name)
+		}
+		names[name] = true
+		field := protoField{
+			Name:   name,
+			Type:   typ,
+			Number: w.numbers.number(model.Name, name, typ),
+		}
+		if jsonName != "" && jsonName != protoJSONName(name) {
+			field.JSONName = jsonName
+		}
+		if len(member.Comments) > 0 {
+			field.Comment = strings.TrimSpace(strings.TrimPrefix(member.Comments[0], "//"))
+		}
+		message.Fields = append(message.Fields, field)
+	}
+
+	// DSL中删除的字段保留编号和名称，类型变化的字段保留旧编号，避免与旧版本的数据冲突
+	for name, number := range w.numbers.Messages[model.Name] {
+		if !names[name] {
+			message.Reserved = append(message.Reserved, protoField{Name: name, Number: number.Number})
+		}
+		for _, retired := range number.Retired {
+			message.Reserved = append(message.Reserved, protoField{Number: retired})
+		}
+	}
+	sort.Slice(message.Reserved, func(i, j int) bool {
+		return message.Reserved[i].Number < message.Reserved[j].Number
+	})
+	return message, nil
+}
+
+// fieldType Go类型转换为proto类型
+func (w *protoWriter) fieldType(goType string) (string, error) {
+	goType = strings.TrimPrefix(goType, "*")
+	if goType == "[]byte" || goType == "[]uint8" {
+		return "bytes", nil
+	}
+	if strings.HasPrefix(goType, "[]") {
+		elem := strings.TrimPrefix(goType[2:], "*")
+		if strings.HasPrefix(elem, "[]") && elem != "[]byte" || strings.HasPrefix(elem, "map[") {
+			return "", fmt.Errorf("不支持嵌套的类型%s", goType)
+		}
+		typ, err := w.fieldType(elem)
+		if err != nil {
+			return "", err
+		}
+		return "repeated " + typ, nil
+	}
+	if strings.HasPrefix(goType, "map[") {
+		key, value, err := splitMapType(goType)
+		if err != nil {
+			return "", err
+		}
+		keyType, ok := protoScalarTypes[key]
+		if !ok || keyType == "float" || keyType == "double" {
+			return "", fmt.Errorf("不支持的map key类型%s", key)
+		}
+		value = strings.TrimPrefix(value, "*")
+		if strings.HasPrefix(value, "[]") && value != "[]byte" || strings.HasPrefix(value, "map[") {
+			return "", fmt.Errorf("不支持嵌套的类型%s", goType)
+		}
+		valueType, err := w.fieldType(value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("map<%s, %s>", keyType, valueType), nil
+	}
+	if typ, ok := protoScalarTypes[goType]; ok {
+		return typ, nil
+	}
+	if typ, ok := protoWellKnownTypes[goType]; ok {
+		w.imports[typ[1]] = true
+		return typ[0], nil
+	}
+	if _, ok := w.models[goType]; ok {
+		return goType, nil
+	}
+	return "", fmt.Errorf("不支持的类型%s", goType)
+}
+
+// service 生成模型的CRUD服务及请求、响应message
+func (w *protoWriter) service(model parser.SpecType) (string, error) {
+	primaryKey := model.PrimaryKey()
+	var pk *parser.SpecMember
+	for i := range model.Members {
+		if model.Members[i].Name == primaryKey {
+			pk = &model.Members[i]
+			break
+		}
+	}
+	if pk == nil {
+		return "", fmt.Errorf("没有主键字段%s", primaryKey)
+	}
+	pkType, err := w.fieldType(pk.Type)
+	if err != nil {
+		return "", fmt.Errorf("主键%s: %w", pk.Name, err)
+	}
+	pkName := utils.SnakeString(pk.Name)
+	plural := pluralName(model.Name)
+	requests := []protoMessage{
+		{Name: "Get" + model.Name + "Request", Fields: []protoField{{Name: pkName, Type: pkType, Number: 1}}},
+		{Name: "Delete" + model.Name + "Request", Fields: []protoField{{Name: pkName, Type: pkType, Number: 1}}},
+		{Name: "List" + plural + "Request", Fields: []protoField{
+			{Name: "page", Type: "int32", Number: 1},
+			{Name: "page_size", Type: "int32", Number: 2},
+		}},
+		{Name: "List" + plural + "Response", Fields: []protoField{
+			{Name: "list", Type: "repeated " + model.Name, Number: 1},
+			{Name: "total", Type: "int64", Number: 2},
+		}},
+	}
+	for _, request := range requests {
+		if _, ok := w.models[request.Name]; ok {
+			return "", fmt.Errorf("生成的message %s与模型重名", request.Name)
+		}
+	}
+	w.messages = append(w.messages, requests...)
+	w.imports["google/protobuf/empty.proto"] = true
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "service %sService {\n", model.Name)
+	fmt.Fprintf(b, "  rpc Create%[1]s(%[1]s) returns (%[1]s);\n", model.Name)
+	fmt.Fprintf(b, "  rpc Get%[1]s(Get%[1]sRequest) returns (%[1]s);\n", model.Name)
+	fmt.Fprintf(b, "  rpc Update%[1]s(%[1]s) returns (%[1]s);\n", model.Name)
+	fmt.Fprintf(b, "  rpc Delete%[1]s(Delete%[1]sRequest) returns (google.protobuf.Empty);\n", model.Name)
+	fmt.Fprintf(b, "  rpc List%[1]s(List%[1]sRequest) returns (List%[1]sResponse);\n", plural)
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+func (w *protoWriter) String(services []string) string {
+	b := &strings.Builder{}
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(b, "package %s;\n", w.option.Package)
+	if len(w.imports) > 0 {
+		b.WriteString("\n")
+		imports := make([]string, 0, len(w.imports))
+		for name := range w.imports {
+			imports = append(imports, name)
+		}
+		sort.Strings(imports)
+		for _, name := range imports {
+			fmt.Fprintf(b, "import %q;\n", name)
+		}
+	}
+	if w.option.GoPackage != "" {
+		fmt.Fprintf(b, "\noption go_package = %q;\n", w.option.GoPackage)
+	}
+	for _, service := range services {
+		b.WriteString("\n")
+		b.WriteString(service)
+	}
+	for _, message := range w.messages {
+		b.WriteString("\n")
+		for _, doc := range message.Docs {
+			fmt.Fprintf(b, "// %s\n", doc)
+		}
+		fmt.Fprintf(b, "message %s {\n", message.Name)
+		for _, field := range message.Fields {
+			fmt.Fprintf(b, "  %s %s = %d", field.Type, field.Name, field.Number)
+			if field.JSONName != "" {
+				fmt.Fprintf(b, " [json_name = %q]", field.JSONName)
+			}
+			b.WriteString(";")
+			if field.Comment != "" {
+				fmt.Fprintf(b, " // %s", field.Comment)
+			}
+			b.WriteString("\n")
+		}
+		for _, field := range message.Reserved {
+			fmt.Fprintf(b, "  reserved %d;\n", field.Number)
+			if field.Name != "" {
+				fmt.Fprintf(b, "  reserved %q;\n", field.Name)
+			}
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// protoJSONName protoc默认的json名称，user_name转换为userName
+func protoJSONName(name string) string {
+	b := &strings.Builder{}
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// splitMapType 拆分map[K]V的K和V
+func splitMapType(goType string) (string, string, error) {
+	depth := 0
+	for i := len("map"); i < len(goType); i++ {
+		switch goType[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return goType[len("map["):i], goType[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("无法解析的类型%s", goType)
+}
+
+// pluralName 模型名的复数形式，用于List方法
+func pluralName(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
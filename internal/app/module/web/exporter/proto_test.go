@@ -0,0 +1,206 @@
+package exporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+)
+
+func TestToProto(t *testing.T) {
+	dsl := `package egoctl
+
+// User 用户
+// @table(name=users)
+type User struct {
+	Uid       int               ` + "`json:\"id\" ego:\"primary_key\"`" + ` // id
+	UserName  string            ` + "`json:\"userName\"`" + ` // 昵称
+	Password  string            ` + "`json:\"-\"`" + `
+	Tags      []string          ` + "`json:\"tags\"`" + `
+	Extra     map[string]int    ` + "`json:\"extra\"`" + `
+	CreatedAt time.Time         ` + "`json:\"createdAt\"`" + `
+	Orders    []*Order          ` + "`json:\"orders\"`" + `
+}
+
+type Order struct {
+	Id     int
+	Amount float64
+}
+`
+	models, err := parser.ParseDSL(dsl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numbers := &ProtoNumbers{}
+	got, err := ToProto(models, ProtoOption{Package: "user.v1", GoPackage: "demo/user/v1"}, numbers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `syntax = "proto3";
+
+package user.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "demo/user/v1";
+
+message Order {
+  int64 id = 1;
+  double amount = 2;
+}
+
+// User 用户
+message User {
+  int64 uid = 1 [json_name = "id"]; // id
+  string user_name = 2; // 昵称
+  repeated string tags = 3;
+  map<string, int64> extra = 4;
+  google.protobuf.Timestamp created_at = 5;
+  repeated Order orders = 6;
+}
+`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// 删除字段后编号保留，新字段不会复用编号
+	filename := filepath.Join(t.TempDir(), ProtoNumbersFile)
+	if err := WriteProtoNumbers(filename, numbers); err != nil {
+		t.Fatal(err)
+	}
+	numbers, err = ReadProtoNumbers(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err = parser.ParseDSL(`package egoctl
+
+type Order struct {
+	Id     int
+	Remark string
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = ToProto(models, ProtoOption{Service: true}, numbers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `syntax = "proto3";
+
+package egoctl;
+
+import "google/protobuf/empty.proto";
+
+service OrderService {
+  rpc CreateOrder(Order) returns (Order);
+  rpc GetOrder(GetOrderRequest) returns (Order);
+  rpc UpdateOrder(Order) returns (Order);
+  rpc DeleteOrder(DeleteOrderRequest) returns (google.protobuf.Empty);
+  rpc ListOrders(ListOrdersRequest) returns (ListOrdersResponse);
+}
+
+message Order {
+  int64 id = 1;
+  string remark = 3;
+  reserved 2;
+  reserved "amount";
+}
+
+message GetOrderRequest {
+  int64 id = 1;
+}
+
+message DeleteOrderRequest {
+  int64 id = 1;
+}
+
+message ListOrdersRequest {
+  int32 page = 1;
+  int32 page_size = 2;
+}
+
+message ListOrdersResponse {
+  repeated Order list = 1;
+  int64 total = 2;
+}
+`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestToProtoTypeChange(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ProtoNumbersFile)
+	numbers, err := ReadProtoNumbers(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err := parser.ParseDSL("package egoctl\n\ntype Order struct {\n\tId     int\n\tAmount float64\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ToProto(models, ProtoOption{}, numbers); err != nil {
+		t.Fatal(err)
+	}
+	if got := numbers.Messages["Order"]["amount"]; got.Number != 2 || got.Type != "double" {
+		t.Fatalf("got amount number %+v", got)
+	}
+
+	// 类型变化后旧编号保留，字段使用新编号
+	models, err = parser.ParseDSL("package egoctl\n\ntype Order struct {\n\tId     int\n\tAmount string\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ToProto(models, ProtoOption{}, numbers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `syntax = "proto3";
+
+package egoctl;
+
+message Order {
+  int64 id = 1;
+  string amount = 3;
+  reserved 2;
+}
+`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := numbers.Messages["Order"]["amount"]; got.Number != 3 || got.Type != "string" || len(got.Retired) != 1 || got.Retired[0] != 2 {
+		t.Fatalf("got amount number %+v", got)
+	}
+
+	// 编号记录写回后仍然保留旧编号，再次变化时使用下一个编号
+	if err = WriteProtoNumbers(filename, numbers); err != nil {
+		t.Fatal(err)
+	}
+	numbers, err = ReadProtoNumbers(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err = parser.ParseDSL("package egoctl\n\ntype Order struct {\n\tId     int\n\tAmount int32\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = ToProto(models, ProtoOption{}, numbers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `syntax = "proto3";
+
+package egoctl;
+
+message Order {
+  int64 id = 1;
+  int32 amount = 4;
+  reserved 2;
+  reserved 3;
+}
+`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
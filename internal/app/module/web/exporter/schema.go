@@ -0,0 +1,327 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+)
+
+const (
+	FormatJSONSchema = "jsonschema"
+	FormatOpenAPI    = "openapi"
+
+	JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	JSONSchemaSuffix  = ".schema.json" // 每个模型一个JSON Schema文件，例如 User.schema.json
+)
+
+// Schema JSON Schema，OpenAPI 3.1的schema与其一致
+type Schema struct {
+	Dialect              string       `json:"$schema,omitempty"`
+	ID                   string       `json:"$id,omitempty"`
+	Ref                  string       `json:"$ref,omitempty"`
+	AllOf                []*Schema    `json:"allOf,omitempty"`
+	Title                string       `json:"title,omitempty"`
+	Description          string       `json:"description,omitempty"`
+	Type                 string       `json:"type,omitempty"`
+	Format               string       `json:"format,omitempty"`
+	Enum                 []string     `json:"enum,omitempty"`
+	Items                *Schema      `json:"items,omitempty"`
+	Properties           NamedSchemas `json:"properties,omitempty"`
+	AdditionalProperties *Schema      `json:"additionalProperties,omitempty"`
+	Required             []string     `json:"required,omitempty"`
+}
+
+// NamedSchema 带名称的schema，用于保持属性、模型在DSL中的顺序
+type NamedSchema struct {
+	Name   string
+	Schema *Schema
+}
+
+type NamedSchemas []NamedSchema
+
+// MarshalJSON 按顺序编码为JSON对象
+func (s NamedSchemas) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, item := range s {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(item.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(item.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// OpenAPIComponents OpenAPI文档中的components部分
+type OpenAPIComponents struct {
+	Components struct {
+		Schemas NamedSchemas `json:"schemas"`
+	} `json:"components"`
+}
+
+// ToJSONSchemas 每个模型生成一个JSON Schema文档，key为文件名，引用其他模型时$ref为该模型的文件名
+func ToJSONSchemas(models []parser.SpecType) (NamedSchemas, error) {
+	c := newSchemaConverter(models, "", JSONSchemaSuffix)
+	output := make(NamedSchemas, 0, len(models))
+	for _, model := range models {
+		schema, err := c.model(model)
+		if err != nil {
+			return nil, err
+		}
+		schema.Dialect = JSONSchemaDialect
+		schema.ID = model.Name + JSONSchemaSuffix
+		output = append(output, NamedSchema{Name: schema.ID, Schema: schema})
+	}
+	return output, nil
+}
+
+// ToOpenAPI 生成OpenAPI的components.schemas，引用其他模型时$ref为#/components/schemas/模型名
+func ToOpenAPI(models []parser.SpecType) (OpenAPIComponents, error) {
+	output := OpenAPIComponents{}
+	c := newSchemaConverter(models, "#/components/schemas/", "")
+	output.Components.Schemas = make(NamedSchemas, 0, len(models))
+	for _, model := range models {
+		schema, err := c.model(model)
+		if err != nil {
+			return output, err
+		}
+		output.Components.Schemas = append(output.Components.Schemas, NamedSchema{Name: model.Name, Schema: schema})
+	}
+	return output, nil
+}
+
+// Export 按格式导出，format为jsonschema或openapi
+func Export(models []parser.SpecType, format string) (interface{}, error) {
+	switch format {
+	case FormatJSONSchema:
+		return ToJSONSchemas(models)
+	case FormatOpenAPI, "":
+		return ToOpenAPI(models)
+	}
+	return nil, fmt.Errorf("不支持的导出格式%s", format)
+}
+
+// oneofValues 与validator一致，oneof的值以空格分隔，含空格的值用单引号括起来
+var oneofValues = regexp.MustCompile(`'[^']*'|\S+`)
+
+type schemaConverter struct {
+	models    map[string]parser.SpecType
+	refPrefix string
+	refSuffix string
+}
+
+// schemaField 展开内嵌结构体后模型的一个属性
+type schemaField struct {
+	name     string
+	depth    int  // 内嵌的层级，模型自身的字段为0
+	tagged   bool // json tag中写了名称
+	required bool
+	schema   *Schema
+}
+
+func newSchemaConverter(models []parser.SpecType, refPrefix string, refSuffix string) *schemaConverter {
+	c := &schemaConverter{
+		models:    make(map[string]parser.SpecType, len(models)),
+		refPrefix: refPrefix,
+		refSuffix: refSuffix,
+	}
+	for _, model := range models {
+		c.models[model.Name] = model
+	}
+	return c
+}
+
+// model 模型转换为object schema，属性名为json tag，binding:"required"的字段为必填，注释为描述
+func (c *schemaConverter) model(model parser.SpecType) (*Schema, error) {
+	schema := &Schema{
+		Title:       model.Name,
+		Description: modelDescription(model),
+		Type:        "object",
+		Properties:  make(NamedSchemas, 0, len(model.Members)),
+	}
+	fields, err := c.fields(model, 0, map[string]bool{model.Name: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range dominantFields(fields) {
+		if field.required {
+			schema.Required = append(schema.Required, field.name)
+		}
+		schema.Properties = append(schema.Properties, NamedSchema{Name: field.name, Schema: field.schema})
+	}
+	return schema, nil
+}
+
+// fields 模型的属性，与encoding/json一致，内嵌且json tag中没有名称的结构体，其属性展开到外层
+func (c *schemaConverter) fields(model parser.SpecType, depth int, visited map[string]bool) ([]schemaField, error) {
+	output := make([]schemaField, 0, len(model.Members))
+	for i, field := range model.ToModelInfos() {
+		name := fieldJSONName(field.FieldName, field.FieldTags["json"])
+		if name == "-" {
+			continue
+		}
+		tagged := strings.TrimSpace(strings.Split(field.FieldTags["json"].Origin, ",")[0]) != ""
+		embedded, ok := c.models[strings.TrimPrefix(field.FieldType, "*")]
+		if model.Members[i].IsInline && !tagged && ok && !visited[embedded.Name] {
+			visited[embedded.Name] = true
+			promoted, err := c.fields(embedded, depth+1, visited)
+			delete(visited, embedded.Name)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, promoted...)
+			continue
+		}
+
+		property, err := c.goType(field.FieldType)
+		if err != nil {
+			return nil, fmt.Errorf("模型%s字段%s: %w", model.Name, field.FieldName, err)
+		}
+		if format, ok := field.FieldTags["format"]; ok && property.Ref == "" {
+			property.Format = format.Origin
+		}
+		required := false
+		for _, rule := range strings.Split(field.FieldTags["binding"].Origin, ",") {
+			rule = strings.TrimSpace(rule)
+			switch {
+			case rule == "required":
+				required = true
+			case strings.HasPrefix(rule, "oneof="):
+				target := property
+				if target.Items != nil {
+					target = target.Items
+				}
+				target.Enum = make([]string, 0)
+				for _, value := range oneofValues.FindAllString(strings.TrimPrefix(rule, "oneof="), -1) {
+					target.Enum = append(target.Enum, strings.Trim(value, "'"))
+				}
+			}
+		}
+		// 字段注释为空时，ToModelInfos使用模型名作为注释
+		if field.FieldComment != model.Name {
+			property.Description = field.FieldComment
+		}
+		// $ref的同级属性会被忽略，描述放在allOf外层
+		if property.Ref != "" && property.Description != "" {
+			property = &Schema{AllOf: []*Schema{{Ref: property.Ref}}, Description: property.Description}
+		}
+		output = append(output, schemaField{name: name, depth: depth, tagged: tagged, required: required, schema: property})
+	}
+	return output, nil
+}
+
+// dominantFields 同名属性与encoding/json一致，取层级最浅的，同一层级只有一个有json tag时取该属性，否则都忽略
+func dominantFields(fields []schemaField) []schemaField {
+	byName := make(map[string][]int)
+	for i, field := range fields {
+		byName[field.name] = append(byName[field.name], i)
+	}
+	dominant := make(map[int]bool, len(byName))
+	for _, indexes := range byName {
+		sort.SliceStable(indexes, func(i, j int) bool {
+			a, b := fields[indexes[i]], fields[indexes[j]]
+			if a.depth != b.depth {
+				return a.depth < b.depth
+			}
+			return a.tagged && !b.tagged
+		})
+		if len(indexes) > 1 && fields[indexes[0]].depth == fields[indexes[1]].depth && fields[indexes[0]].tagged == fields[indexes[1]].tagged {
+			continue
+		}
+		dominant[indexes[0]] = true
+	}
+	output := make([]schemaField, 0, len(dominant))
+	for i, field := range fields {
+		if dominant[i] {
+			output = append(output, field)
+		}
+	}
+	return output
+}
+
+// goType Go类型转换为schema
+func (c *schemaConverter) goType(goType string) (*Schema, error) {
+	goType = strings.TrimPrefix(goType, "*")
+	switch goType {
+	case "bool":
+		return &Schema{Type: "boolean"}, nil
+	case "string":
+		return &Schema{Type: "string"}, nil
+	case "int", "uint", "int8", "int16", "uint8", "uint16", "uint32", "uint64", "byte":
+		return &Schema{Type: "integer"}, nil
+	case "int32", "rune":
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case "int64":
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}, nil
+	case "float64":
+		return &Schema{Type: "number"}, nil
+	case "time.Time":
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case "[]byte", "[]uint8":
+		return &Schema{Type: "string", Format: "byte"}, nil
+	case "interface{}", "any":
+		return &Schema{}, nil
+	}
+	if strings.HasPrefix(goType, "[]") {
+		items, err := c.goType(goType[2:])
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	}
+	if strings.HasPrefix(goType, "map[") {
+		_, value, err := splitMapType(goType)
+		if err != nil {
+			return nil, err
+		}
+		additional, err := c.goType(value)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: additional}, nil
+	}
+	if _, ok := c.models[goType]; ok {
+		return &Schema{Ref: c.refPrefix + goType + c.refSuffix}, nil
+	}
+	return nil, fmt.Errorf("不支持的类型%s", goType)
+}
+
+// modelDescription 模型的描述，为结构体上方除注解外的注释
+func modelDescription(model parser.SpecType) string {
+	docs := make([]string, 0, len(model.Docs))
+	for _, doc := range model.Docs {
+		doc = strings.TrimSpace(strings.TrimPrefix(doc, "//"))
+		if doc == "" || strings.HasPrefix(doc, "@") {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return strings.Join(docs, "\n")
+}
+
+// fieldJSONName 字段的json名称，与encoding/json一致，没有json tag时为字段名
+func fieldJSONName(fieldName string, tag parser.SpecTag) string {
+	name := strings.TrimSpace(strings.Split(tag.Origin, ",")[0])
+	if name == "" {
+		return fieldName
+	}
+	return name
+}
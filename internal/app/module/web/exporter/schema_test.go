@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+)
+
+func TestToOpenAPI(t *testing.T) {
+	models, err := parser.ParseDSL(`package egoctl
+
+// User 用户
+// @table(name=users)
+type User struct {
+	Uid      int64     ` + "`json:\"id\" binding:\"required\"`" + ` // id
+	UserName string    ` + "`json:\"userName,omitempty\" binding:\"required\"`" + `
+	Email    string    ` + "`json:\"email\" format:\"email\"`" + `
+	Status   string    ` + "`json:\"status\" binding:\"oneof=active banned\"`" + `
+	Password string    ` + "`json:\"-\"`" + `
+	Extra    map[string]int
+	Orders   []*Order  ` + "`json:\"orders\"`" + `
+}
+
+type Order struct {
+	Id     int
+	Amount float64
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := ToOpenAPI(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"components":{"schemas":{` +
+		`"Order":{"title":"Order","type":"object","properties":{"Id":{"type":"integer"},"Amount":{"type":"number"}}},` +
+		`"User":{"title":"User","description":"User 用户","type":"object","properties":{` +
+		`"id":{"description":"id","type":"integer","format":"int64"},` +
+		`"userName":{"type":"string"},` +
+		`"email":{"type":"string","format":"email"},` +
+		`"status":{"type":"string","enum":["active","banned"]},` +
+		`"Extra":{"type":"object","additionalProperties":{"type":"integer"}},` +
+		`"orders":{"type":"array","items":{"$ref":"#/components/schemas/Order"}}},` +
+		`"required":["id","userName"]}}}}`
+	if string(got) != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	schemas, err := ToJSONSchemas(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 2 || schemas[1].Name != "User.schema.json" || schemas[1].Schema.Dialect != JSONSchemaDialect {
+		t.Fatalf("got schemas %+v", schemas)
+	}
+	if ref := schemas[1].Schema.Properties[5].Schema.Items.Ref; ref != "Order.schema.json" {
+		t.Fatalf("got ref %s, want Order.schema.json", ref)
+	}
+}
+
+func TestToOpenAPIEmbedded(t *testing.T) {
+	models, err := parser.ParseDSL(`package egoctl
+
+type Base struct {
+	Id    int64  ` + "`json:\"id\" binding:\"required\"`" + `
+	Ctime int64  ` + "`json:\"ctime\"`" + `
+	Name  string ` + "`json:\"name\"`" + `
+}
+
+type Audit struct {
+	Name     string ` + "`json:\"name\"`" + `
+	Operator string ` + "`json:\"operator\"`" + `
+}
+
+type Order struct {
+	Base
+	*Audit
+	Ctime  string ` + "`json:\"ctime\"`" + `
+	Level  string ` + "`json:\"level\" binding:\"oneof='very high' low\"`" + `
+	Owner  Base   ` + "`json:\"owner\"`" + ` // 下单人
+	Parent *Order // 上级订单
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := ToOpenAPI(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(output.Components.Schemas[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Base和Audit的name在同一层级冲突，都被忽略；外层的ctime覆盖Base.ctime
+	want := `{"Name":"Order","Schema":{"title":"Order","type":"object","properties":{` +
+		`"id":{"type":"integer","format":"int64"},` +
+		`"operator":{"type":"string"},` +
+		`"ctime":{"type":"string"},` +
+		`"level":{"type":"string","enum":["very high","low"]},` +
+		`"owner":{"allOf":[{"$ref":"#/components/schemas/Base"}],"description":"下单人"},` +
+		`"Parent":{"allOf":[{"$ref":"#/components/schemas/Order"}],"description":"上级订单"}},` +
+		`"required":["id"]}}`
+	if string(got) != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
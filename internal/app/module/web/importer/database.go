@@ -0,0 +1,406 @@
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/gotomicro/egoctl/internal/utils"
+)
+
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite3"
+)
+
+// PullOption 从数据库导入DSL的参数
+type PullOption struct {
+	Driver string // mysql、postgres、sqlite3
+	DSN    string
+	Schema string // postgres的schema，默认为public
+	Filter Filter
+}
+
+// Filter 导入时过滤表和列，规则支持path.Match的通配符
+type Filter struct {
+	Tables         []string // 只导入匹配的表，为空时导入所有表
+	ExcludeTables  []string // 不导入匹配的表
+	ExcludeColumns []string // 不导入匹配的列，规则为 列名 或 表名.列名
+}
+
+func (f Filter) matchTable(name string) bool {
+	if len(f.Tables) > 0 && !matchAny(f.Tables, name) {
+		return false
+	}
+	return !matchAny(f.ExcludeTables, name)
+}
+
+func (f Filter) matchColumn(tableName, columnName string) bool {
+	return !matchAny(f.ExcludeColumns, columnName) && !matchAny(f.ExcludeColumns, tableName+"."+columnName)
+}
+
+// apply 过滤表和列，过滤后没有列的表也会被去掉
+func (f Filter) apply(tables []table) []table {
+	output := make([]table, 0, len(tables))
+	for _, t := range tables {
+		if !f.matchTable(t.name) {
+			continue
+		}
+		columns := make([]column, 0, len(t.columns))
+		for _, c := range t.columns {
+			if f.matchColumn(t.name, c.name) {
+				columns = append(columns, c)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		t.columns = columns
+		output = append(output, t)
+	}
+	return output
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Pull 连接数据库，读取表、列、索引和注释，转换为模型
+func Pull(option PullOption) ([]Model, error) {
+	db, err := sql.Open(sqlDriverName(option.Driver), option.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败, err: %w", err)
+	}
+	defer db.Close()
+	if err = db.Ping(); err != nil {
+		return nil, fmt.Errorf("连接数据库失败, err: %w", err)
+	}
+	return pull(db, option)
+}
+
+// pull 按数据库类型读取表结构，过滤后转换为模型
+func pull(db *sql.DB, option PullOption) ([]Model, error) {
+	var (
+		tables []table
+		err    error
+	)
+	switch option.Driver {
+	case DriverMySQL:
+		tables, err = pullMySQL(db, option)
+	case DriverPostgres:
+		tables, err = pullPostgres(db, option)
+	case DriverSQLite:
+		tables, err = pullSQLite(db, option)
+	default:
+		return nil, fmt.Errorf("不支持的数据库类型: %s", option.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tables = option.Filter.apply(tables)
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("没有匹配的表")
+	}
+	models := make([]Model, 0, len(tables))
+	modelNames := make(map[string]bool)
+	for _, t := range tables {
+		models = append(models, t.model(modelNames))
+	}
+	return models, nil
+}
+
+// sqlDriverName database/sql中注册的驱动名称，sqlite使用不依赖cgo的modernc.org/sqlite，注册名为sqlite
+func sqlDriverName(driver string) string {
+	if driver == DriverSQLite {
+		return "sqlite"
+	}
+	return driver
+}
+
+// PullDSL 将数据库中的表结构转换为DSL
+func PullDSL(option PullOption) (string, error) {
+	models, err := Pull(option)
+	if err != nil {
+		return "", err
+	}
+	return Generate(models)
+}
+
+// queryTables 查询表名和表注释，只保留Filter匹配的表
+func queryTables(db *sql.DB, filter Filter, query string, args ...interface{}) ([]table, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询表失败, err: %w", err)
+	}
+	defer rows.Close()
+	tables := make([]table, 0)
+	for rows.Next() {
+		t := table{}
+		if err = rows.Scan(&t.name, &t.comment); err != nil {
+			return nil, fmt.Errorf("查询表失败, err: %w", err)
+		}
+		if filter.matchTable(t.name) {
+			tables = append(tables, t)
+		}
+	}
+	return tables, rows.Err()
+}
+
+// queryIndexes 查询索引，每行为 索引名、是否唯一、是否主键、列名，同一索引的列需要相邻
+func (t *table) queryIndexes(db *sql.DB, query string, args ...interface{}) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("查询表%s的索引失败, err: %w", t.name, err)
+	}
+	defer rows.Close()
+	type index struct {
+		name    string
+		unique  bool
+		primary bool
+		columns []string
+	}
+	indexes := make([]*index, 0)
+	for rows.Next() {
+		var name, columnName string
+		var unique, primary bool
+		if err = rows.Scan(&name, &unique, &primary, &columnName); err != nil {
+			return fmt.Errorf("查询表%s的索引失败, err: %w", t.name, err)
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].name != name {
+			indexes = append(indexes, &index{name: name, unique: unique, primary: primary})
+		}
+		last := indexes[len(indexes)-1]
+		last.columns = append(last.columns, columnName)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if idx.primary {
+			t.setPrimaryKey(idx.columns)
+		} else {
+			t.addIndex(idx.name, idx.unique, idx.columns)
+		}
+	}
+	return nil
+}
+
+func pullMySQL(db *sql.DB, option PullOption) ([]table, error) {
+	dsn, err := utils.ParseDSN(option.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("解析DSN失败, err: %w", err)
+	}
+	if dsn.DBName == "" {
+		return nil, fmt.Errorf("DSN中缺少数据库名")
+	}
+	tables, err := queryTables(db, option.Filter, `SELECT TABLE_NAME, TABLE_COMMENT FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME`, dsn.DBName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		t := &tables[i]
+		rows, err := db.Query(`SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, EXTRA, COLUMN_DEFAULT, COLUMN_COMMENT
+FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, dsn.DBName, t.name)
+		if err != nil {
+			return nil, fmt.Errorf("查询表%s的列失败, err: %w", t.name, err)
+		}
+		for rows.Next() {
+			var name, dataType, columnType, nullable, key, extra, comment string
+			var defaultValue sql.NullString
+			if err = rows.Scan(&name, &dataType, &columnType, &nullable, &key, &extra, &defaultValue, &comment); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("查询表%s的列失败, err: %w", t.name, err)
+			}
+			args := ""
+			if start, end := strings.Index(columnType, "("), strings.Index(columnType, ")"); start >= 0 && end > start {
+				args = columnType[start+1 : end]
+			}
+			c := column{
+				name:          name,
+				sqlType:       columnType,
+				goType:        mysqlGoType(dataType, args, strings.Contains(columnType, "unsigned")),
+				notNull:       nullable == "NO",
+				autoIncrement: strings.Contains(extra, "auto_increment"),
+				primaryKey:    key == "PRI",
+				comment:       comment,
+			}
+			if defaultValue.Valid {
+				// COLUMN_DEFAULT中的字符串没有引号，函数的EXTRA为DEFAULT_GENERATED
+				c.hasDefault, c.defaultValue = true, defaultValue.String
+				expression := strings.Contains(extra, "DEFAULT_GENERATED") || strings.HasPrefix(strings.ToUpper(defaultValue.String), "CURRENT_TIMESTAMP")
+				if !expression && (c.goType == "string" || c.goType == "[]byte") {
+					c.defaultValue = "'" + strings.ReplaceAll(defaultValue.String, "'", "''") + "'"
+				}
+			}
+			t.columns = append(t.columns, c)
+		}
+		rows.Close()
+		err = t.queryIndexes(db, `SELECT INDEX_NAME, NON_UNIQUE = 0, INDEX_NAME = 'PRIMARY', COLUMN_NAME
+FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX`, dsn.DBName, t.name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+func pullPostgres(db *sql.DB, option PullOption) ([]table, error) {
+	schema := option.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	tables, err := queryTables(db, option.Filter, `SELECT table_name, COALESCE(obj_description(format('%I.%I', table_schema, table_name)::regclass, 'pg_class'), '')
+FROM information_schema.tables WHERE table_schema = $1 AND table_type = 'BASE TABLE' ORDER BY table_name`, schema)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		t := &tables[i]
+		rows, err := db.Query(`SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull, a.attidentity <> '',
+COALESCE(pg_get_expr(d.adbin, d.adrelid), ''), COALESCE(col_description(a.attrelid, a.attnum), '')
+FROM pg_attribute a LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = format('%I.%I', $1::text, $2::text)::regclass AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`, schema, t.name)
+		if err != nil {
+			return nil, fmt.Errorf("查询表%s的列失败, err: %w", t.name, err)
+		}
+		for rows.Next() {
+			var c column
+			var identity bool
+			var defaultValue string
+			if err = rows.Scan(&c.name, &c.sqlType, &c.notNull, &identity, &defaultValue, &c.comment); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("查询表%s的列失败, err: %w", t.name, err)
+			}
+			c.goType = postgresGoType(c.sqlType)
+			switch {
+			case identity || strings.HasPrefix(defaultValue, "nextval("):
+				c.autoIncrement = true
+			case defaultValue != "":
+				// 去掉字符串默认值的类型转换，例如 'abc'::character varying
+				if end := strings.LastIndex(defaultValue, "'::"); strings.HasPrefix(defaultValue, "'") && end > 0 {
+					defaultValue = defaultValue[:end+1]
+				}
+				c.hasDefault, c.defaultValue = true, defaultValue
+			}
+			t.columns = append(t.columns, c)
+		}
+		rows.Close()
+		err = t.queryIndexes(db, `SELECT i.relname, ix.indisunique, ix.indisprimary, a.attname
+FROM pg_index ix JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = ANY(ix.indkey)
+WHERE ix.indrelid = format('%I.%I', $1::text, $2::text)::regclass ORDER BY i.relname, a.attnum`, schema, t.name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+func pullSQLite(db *sql.DB, option PullOption) ([]table, error) {
+	tables, err := queryTables(db, option.Filter, `SELECT name, '' FROM sqlite_master
+WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		t := &tables[i]
+		rows, err := db.Query(`SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, t.name)
+		if err != nil {
+			return nil, fmt.Errorf("查询表%s的列失败, err: %w", t.name, err)
+		}
+		primaryKeys := 0
+		for rows.Next() {
+			var c column
+			var defaultValue sql.NullString
+			var pk int
+			if err = rows.Scan(&c.name, &c.sqlType, &c.notNull, &defaultValue, &pk); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("查询表%s的列失败, err: %w", t.name, err)
+			}
+			c.sqlType = strings.ToLower(c.sqlType)
+			c.goType = sqliteGoType(c.sqlType)
+			c.primaryKey = pk > 0
+			c.hasDefault, c.defaultValue = defaultValue.Valid, defaultValue.String
+			if c.primaryKey {
+				primaryKeys++
+			}
+			t.columns = append(t.columns, c)
+		}
+		rows.Close()
+		// 单独的INTEGER主键是rowid的别名，会自增
+		if primaryKeys == 1 {
+			for j := range t.columns {
+				if t.columns[j].primaryKey && t.columns[j].sqlType == "integer" {
+					t.columns[j].autoIncrement = true
+				}
+			}
+		}
+		err = t.queryIndexes(db, `SELECT il.name, il."unique", il.origin = 'pk', ii.name
+FROM pragma_index_list(?) il JOIN pragma_index_info(il.name) ii ORDER BY il.name, ii.seqno`, t.name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// postgresGoType postgres的format_type转换为Go类型
+func postgresGoType(formatType string) string {
+	t := strings.ToLower(formatType)
+	switch {
+	case strings.HasSuffix(t, "[]"):
+		return "string"
+	case t == "smallint":
+		return "int16"
+	case t == "integer":
+		return "int"
+	case t == "bigint":
+		return "int64"
+	case t == "real":
+		return "float32"
+	case t == "double precision", strings.HasPrefix(t, "numeric"):
+		return "float64"
+	case t == "boolean":
+		return "bool"
+	case t == "date", strings.HasPrefix(t, "timestamp"):
+		return "time.Time"
+	case t == "bytea":
+		return "[]byte"
+	}
+	return "string"
+}
+
+// sqliteGoType 按sqlite的类型亲和性规则转换为Go类型
+func sqliteGoType(sqlType string) string {
+	t := strings.ToLower(sqlType)
+	switch {
+	case strings.Contains(t, "bool"):
+		return "bool"
+	case strings.Contains(t, "int"):
+		return "int64"
+	case strings.Contains(t, "char"), strings.Contains(t, "clob"), strings.Contains(t, "text"):
+		return "string"
+	case t == "", strings.Contains(t, "blob"):
+		return "[]byte"
+	case strings.Contains(t, "real"), strings.Contains(t, "floa"), strings.Contains(t, "doub"):
+		return "float64"
+	case strings.Contains(t, "date"), strings.Contains(t, "time"):
+		return "time.Time"
+	case strings.Contains(t, "dec"), strings.Contains(t, "num"):
+		return "float64"
+	}
+	return "string"
+}
@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeResult 一次查询返回的列和行
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeResponder 根据SQL和参数返回结果，用于在没有数据库的情况下测试information_schema的查询和映射
+type fakeResponder func(query string, args []driver.Value) (fakeResult, error)
+
+var (
+	fakeOnce       sync.Once
+	fakeLock       sync.Mutex
+	fakeResponders = make(map[string]fakeResponder)
+)
+
+// openFakeDB 打开一个由responder返回查询结果的数据库
+func openFakeDB(t *testing.T, responder fakeResponder) *sql.DB {
+	t.Helper()
+	fakeOnce.Do(func() {
+		sql.Register("importer-fake", fakeDriver{})
+	})
+	fakeLock.Lock()
+	fakeResponders[t.Name()] = responder
+	fakeLock.Unlock()
+	db, err := sql.Open("importer-fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeLock.Lock()
+		delete(fakeResponders, t.Name())
+		fakeLock.Unlock()
+	})
+	return db
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeLock.Lock()
+	defer fakeLock.Unlock()
+	responder, ok := fakeResponders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake db %s", name)
+	}
+	return fakeConn{responder: responder}, nil
+}
+
+type fakeConn struct {
+	responder fakeResponder
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{responder: c.responder, query: query}, nil
+}
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	responder fakeResponder
+	query     string
+}
+
+func (s fakeStmt) Close() error { return nil }
+
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	result, err := s.responder(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{result: result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	pos    int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+// fakeQuery 按SQL中的关键字和表名选择结果
+type fakeQuery struct {
+	contains string
+	table    string // 为空时不检查表名参数
+	result   fakeResult
+}
+
+func fakeQueries(t *testing.T, queries []fakeQuery) fakeResponder {
+	return func(query string, args []driver.Value) (fakeResult, error) {
+		for _, q := range queries {
+			if !strings.Contains(query, q.contains) {
+				continue
+			}
+			if q.table != "" && (len(args) < 2 || args[1] != q.table) {
+				continue
+			}
+			return q.result, nil
+		}
+		t.Errorf("unexpected query %s %v", query, args)
+		return fakeResult{}, fmt.Errorf("unexpected query")
+	}
+}
@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"path/filepath"
+	"testing"
+)
+
+func TestPullSQLite(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open(sqlDriverName(DriverSQLite), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE user (id INTEGER PRIMARY KEY, user_name VARCHAR(64) NOT NULL DEFAULT '', password TEXT, ctime DATETIME)",
+		"CREATE UNIQUE INDEX uniq_user_name ON user (user_name)",
+		"CREATE TABLE audit_log (id INTEGER PRIMARY KEY, body TEXT)",
+		"CREATE TABLE user_info (user_id INTEGER, UserId INTEGER)",
+		"CREATE TABLE UserInfo (id INTEGER)",
+	} {
+		if _, err = db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := PullDSL(PullOption{
+		Driver: DriverSQLite,
+		DSN:    dsn,
+		Filter: Filter{Tables: []string{"user"}, ExcludeColumns: []string{"user.password"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"// @table(name=user)\n" +
+		"type User struct {\n" +
+		"\tId       int64     `gorm:\"type:integer;primary_key;AUTO_INCREMENT\" json:\"id\" ego:\"primary_key\"`\n" +
+		"\tUserName string    `gorm:\"type:varchar(64);not null;default:'';unique_index:uniq_user_name\" json:\"userName\"`\n" +
+		"\tCtime    time.Time `gorm:\"type:datetime\" json:\"ctime\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// 只有大小写或下划线不同的表名、列名加上数字后缀
+	got, err = PullDSL(PullOption{
+		Driver: DriverSQLite,
+		DSN:    dsn,
+		Filter: Filter{ExcludeTables: []string{"audit_*", "user"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "package egoctl\n" +
+		"\n" +
+		"// @table(name=UserInfo)\n" +
+		"type UserInfo struct {\n" +
+		"\tId int64 `gorm:\"type:integer\" json:\"id\"`\n" +
+		"}\n" +
+		"\n" +
+		"// @table(name=user_info)\n" +
+		"type UserInfo2 struct {\n" +
+		"\tUserId  int64 `gorm:\"type:integer\" json:\"userId\"`\n" +
+		"\tUserId2 int64 `gorm:\"column:UserId;type:integer\" json:\"userId2\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	_, err = Pull(PullOption{Driver: DriverSQLite, DSN: dsn, Filter: Filter{Tables: []string{"order"}}})
+	if err == nil {
+		t.Fatal("want error when no table matches")
+	}
+}
+
+func TestPullMySQL(t *testing.T) {
+	columns := []string{"COLUMN_NAME", "DATA_TYPE", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_KEY", "EXTRA", "COLUMN_DEFAULT", "COLUMN_COMMENT"}
+	indexColumns := []string{"INDEX_NAME", "UNIQUE", "PRIMARY", "COLUMN_NAME"}
+	db := openFakeDB(t, fakeQueries(t, []fakeQuery{
+		{contains: "information_schema.TABLES", result: fakeResult{
+			columns: []string{"TABLE_NAME", "TABLE_COMMENT"},
+			rows:    [][]driver.Value{{"audit_log", ""}, {"user", "用户表"}},
+		}},
+		{contains: "information_schema.COLUMNS", table: "user", result: fakeResult{columns: columns, rows: [][]driver.Value{
+			{"id", "bigint", "bigint(20) unsigned", "NO", "PRI", "auto_increment", nil, "id"},
+			{"user_name", "varchar", "varchar(64)", "NO", "UNI", "", "", "昵称"},
+			{"is_admin", "tinyint", "tinyint(1)", "NO", "", "", "0", ""},
+			{"score", "decimal", "decimal(10,2)", "YES", "", "", nil, ""},
+			{"uid", "int", "int(11)", "NO", "MUL", "", nil, ""},
+			{"status", "int", "int(11)", "NO", "", "", "1", ""},
+			{"ctime", "datetime", "datetime", "NO", "", "DEFAULT_GENERATED", "CURRENT_TIMESTAMP", ""},
+			{"password", "varchar", "varchar(64)", "NO", "", "", nil, ""},
+		}}},
+		{contains: "information_schema.STATISTICS", table: "user", result: fakeResult{columns: indexColumns, rows: [][]driver.Value{
+			{"PRIMARY", int64(1), int64(1), "id"},
+			{"idx_uid_status", int64(0), int64(0), "uid"},
+			{"idx_uid_status", int64(0), int64(0), "status"},
+			{"uniq_user_name", int64(1), int64(0), "user_name"},
+		}}},
+	}))
+	models, err := pull(db, PullOption{
+		Driver: DriverMySQL,
+		DSN:    "root:root@tcp(127.0.0.1:3306)/shop?charset=utf8mb4",
+		Filter: Filter{ExcludeTables: []string{"audit_*"}, ExcludeColumns: []string{"password"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"// User 用户表\n" +
+		"// @table(name=user)\n" +
+		"type User struct {\n" +
+		"\tId       uint64    `gorm:\"type:bigint(20) unsigned;primary_key;AUTO_INCREMENT;not null\" json:\"id\" ego:\"primary_key\"` // id\n" +
+		"\tUserName string    `gorm:\"type:varchar(64);not null;default:'';unique_index:uniq_user_name\" json:\"userName\"`         // 昵称\n" +
+		"\tIsAdmin  bool      `gorm:\"type:tinyint(1);not null;default:0\" json:\"isAdmin\"`\n" +
+		"\tScore    float64   `gorm:\"type:decimal(10,2)\" json:\"score\"`\n" +
+		"\tUid      int       `gorm:\"type:int(11);not null;index:idx_uid_status\" json:\"uid\"`\n" +
+		"\tStatus   int       `gorm:\"type:int(11);not null;default:1;index:idx_uid_status\" json:\"status\"`\n" +
+		"\tCtime    time.Time `gorm:\"type:datetime;not null;default:CURRENT_TIMESTAMP\" json:\"ctime\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	_, err = pull(db, PullOption{Driver: DriverMySQL, DSN: "root:root@tcp(127.0.0.1:3306)/"})
+	if err == nil {
+		t.Fatal("want error when the DSN has no database name")
+	}
+}
+
+func TestPullPostgres(t *testing.T) {
+	columns := []string{"attname", "format_type", "attnotnull", "identity", "default", "comment"}
+	db := openFakeDB(t, fakeQueries(t, []fakeQuery{
+		{contains: "information_schema.tables", result: fakeResult{
+			columns: []string{"table_name", "comment"},
+			rows:    [][]driver.Value{{"orders", "订单"}},
+		}},
+		{contains: "pg_attribute a LEFT JOIN", table: "orders", result: fakeResult{columns: columns, rows: [][]driver.Value{
+			{"id", "bigint", true, true, "", ""},
+			{"seq", "integer", true, false, "nextval('orders_seq_seq'::regclass)", ""},
+			{"title", "character varying(64)", true, false, "'abc'::character varying", "标题"},
+			{"amount", "numeric(10,2)", false, false, "", ""},
+			{"paid", "boolean", true, false, "false", ""},
+			{"tags", "text[]", false, false, "", ""},
+			{"data", "bytea", false, false, "", ""},
+			{"created_at", "timestamp with time zone", true, false, "now()", ""},
+		}}},
+		{contains: "pg_index", table: "orders", result: fakeResult{columns: []string{"relname", "indisunique", "indisprimary", "attname"}, rows: [][]driver.Value{
+			{"idx_orders_title", false, false, "title"},
+			{"orders_pkey", true, true, "id"},
+			{"uniq_orders_seq", true, false, "seq"},
+		}}},
+	}))
+	models, err := pull(db, PullOption{Driver: DriverPostgres})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"// Orders 订单\n" +
+		"// @table(name=orders)\n" +
+		"type Orders struct {\n" +
+		"\tId        int64     `gorm:\"type:bigint;primary_key;AUTO_INCREMENT;not null\" json:\"id\" ego:\"primary_key\"`\n" +
+		"\tSeq       int       `gorm:\"type:integer;AUTO_INCREMENT;not null;unique_index:uniq_orders_seq\" json:\"seq\"`\n" +
+		"\tTitle     string    `gorm:\"type:character varying(64);not null;default:'abc';index:idx_orders_title\" json:\"title\"` // 标题\n" +
+		"\tAmount    float64   `gorm:\"type:numeric(10,2)\" json:\"amount\"`\n" +
+		"\tPaid      bool      `gorm:\"type:boolean;not null;default:false\" json:\"paid\"`\n" +
+		"\tTags      string    `gorm:\"type:text[]\" json:\"tags\"`\n" +
+		"\tData      []byte    `gorm:\"type:bytea\" json:\"data\"`\n" +
+		"\tCreatedAt time.Time `gorm:\"type:timestamp with time zone;not null;default:now()\" json:\"createdAt\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPostgresGoType(t *testing.T) {
+	tests := map[string]string{
+		"smallint":                    "int16",
+		"integer":                     "int",
+		"bigint":                      "int64",
+		"real":                        "float32",
+		"double precision":            "float64",
+		"numeric(10,2)":               "float64",
+		"boolean":                     "bool",
+		"date":                        "time.Time",
+		"timestamp without time zone": "time.Time",
+		"bytea":                       "[]byte",
+		"integer[]":                   "string",
+		"uuid":                        "string",
+	}
+	for formatType, want := range tests {
+		if got := postgresGoType(formatType); got != want {
+			t.Errorf("%s: got %s, want %s", formatType, got, want)
+		}
+	}
+}
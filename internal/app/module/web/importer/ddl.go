@@ -0,0 +1,565 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// InfoDDL 从DDL导入DSL的参数
+type InfoDDL struct {
+	DDL string `json:"ddl" binding:"required"` // CREATE TABLE语句
+}
+
+type tokenKind int
+
+const (
+	tokenIdent  tokenKind = iota // 关键字或未加引号的标识符
+	tokenQuoted                  // `标识符`
+	tokenString                  // '字符串'
+	tokenNumber
+	tokenSymbol
+)
+
+type sqlToken struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// keyword 未加引号的标识符按关键字比较，不区分大小写
+func (t sqlToken) keyword(word string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, word)
+}
+
+func (t sqlToken) symbol(s string) bool {
+	return t.kind == tokenSymbol && t.text == s
+}
+
+func (t sqlToken) name() bool {
+	return t.kind == tokenIdent || t.kind == tokenQuoted
+}
+
+// sql 还原为SQL文本，用于type、default等tag
+func (t sqlToken) sql() string {
+	if t.kind == tokenString {
+		return "'" + strings.ReplaceAll(t.text, "'", "''") + "'"
+	}
+	return t.text
+}
+
+// FromDDL 解析MySQL的CREATE TABLE语句，其他语句会被忽略
+func FromDDL(ddl string) ([]Model, error) {
+	tokens, err := tokenize(ddl)
+	if err != nil {
+		return nil, err
+	}
+	p := &ddlParser{tokens: tokens}
+	models := make([]Model, 0)
+	modelNames := make(map[string]bool)
+	for !p.eof() {
+		if p.accept("CREATE") {
+			p.accept("TEMPORARY")
+			if p.accept("TABLE") {
+				t, err := p.parseTable()
+				if err != nil {
+					return nil, err
+				}
+				models = append(models, t.model(modelNames))
+				continue
+			}
+		}
+		p.skipStatement()
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("未找到CREATE TABLE语句")
+	}
+	return models, nil
+}
+
+// DDLToDSL 将CREATE TABLE语句转换为DSL
+func DDLToDSL(ddl string) (string, error) {
+	models, err := FromDDL(ddl)
+	if err != nil {
+		return "", err
+	}
+	return Generate(models)
+}
+
+type ddlParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (p *ddlParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *ddlParser) peek() sqlToken {
+	if p.eof() {
+		line := 0
+		if len(p.tokens) > 0 {
+			line = p.tokens[len(p.tokens)-1].line
+		}
+		return sqlToken{kind: tokenSymbol, text: "EOF", line: line}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) accept(words ...string) bool {
+	for i, word := range words {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].keyword(word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *ddlParser) expectSymbol(s string) error {
+	token := p.peek()
+	if !token.symbol(s) {
+		return fmt.Errorf("第%d行: 期望 %s, 实际为 %s", token.line, s, token.text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *ddlParser) skipStatement() {
+	for !p.eof() {
+		token := p.tokens[p.pos]
+		p.pos++
+		if token.symbol(";") {
+			return
+		}
+	}
+}
+
+// parseName 解析表名，忽略库名前缀
+func (p *ddlParser) parseName() (string, error) {
+	token := p.peek()
+	if !token.name() {
+		return "", fmt.Errorf("第%d行: 期望表名, 实际为 %s", token.line, token.text)
+	}
+	p.pos++
+	name := token.text
+	for p.peek().symbol(".") {
+		p.pos++
+		token = p.peek()
+		if !token.name() {
+			return "", fmt.Errorf("第%d行: 期望表名, 实际为 %s", token.line, token.text)
+		}
+		p.pos++
+		name = token.text
+	}
+	return name, nil
+}
+
+func (p *ddlParser) parseTable() (table, error) {
+	p.accept("IF", "NOT", "EXISTS")
+	line := p.peek().line
+	name, err := p.parseName()
+	if err != nil {
+		return table{}, err
+	}
+	if p.peek().keyword("LIKE") || p.peek().keyword("AS") || p.peek().keyword("SELECT") {
+		return table{}, fmt.Errorf("第%d行: 表%s不支持LIKE或AS SELECT建表", line, name)
+	}
+	if err = p.expectSymbol("("); err != nil {
+		return table{}, err
+	}
+
+	// 约束可能写在列之前，全部读取后再处理
+	t := table{name: name}
+	constraints := make([][]sqlToken, 0)
+	for {
+		def, err := p.definition()
+		if err != nil {
+			return table{}, err
+		}
+		if len(def) > 0 {
+			if isConstraint(def[0]) {
+				constraints = append(constraints, def)
+			} else {
+				c, err := parseColumn(def)
+				if err != nil {
+					return table{}, err
+				}
+				t.columns = append(t.columns, c)
+			}
+		}
+		token := p.peek()
+		p.pos++
+		if token.symbol(")") {
+			break
+		}
+		if !token.symbol(",") {
+			return table{}, fmt.Errorf("第%d行: 表%s的定义没有结束", token.line, name)
+		}
+	}
+	if len(t.columns) == 0 {
+		return table{}, fmt.Errorf("第%d行: 表%s没有定义列", line, name)
+	}
+	for _, def := range constraints {
+		t.addConstraint(def)
+	}
+	// 表选项，只关心COMMENT，语句末尾缺少分号时遇到下一个CREATE结束
+	for !p.eof() && !p.peek().symbol(";") && !p.peek().keyword("CREATE") {
+		if p.accept("COMMENT") {
+			if p.peek().symbol("=") {
+				p.pos++
+			}
+			if p.peek().kind == tokenString {
+				t.comment = p.peek().text
+				p.pos++
+			}
+			continue
+		}
+		p.pos++
+	}
+	if p.peek().symbol(";") {
+		p.pos++
+	}
+	return t, nil
+}
+
+// definition 读取一个列或约束的定义，直到同一层级的逗号或右括号
+func (p *ddlParser) definition() ([]sqlToken, error) {
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		token := p.tokens[p.pos]
+		switch {
+		case token.symbol("("):
+			depth++
+		case token.symbol(")"):
+			if depth == 0 {
+				return p.tokens[start:p.pos], nil
+			}
+			depth--
+		case token.symbol(",") && depth == 0:
+			return p.tokens[start:p.pos], nil
+		case token.symbol(";"):
+			return nil, fmt.Errorf("第%d行: 括号不匹配", token.line)
+		}
+		p.pos++
+	}
+	return nil, fmt.Errorf("第%d行: CREATE TABLE语句没有结束", p.peek().line)
+}
+
+func isConstraint(token sqlToken) bool {
+	for _, word := range []string{"PRIMARY", "UNIQUE", "KEY", "INDEX", "CONSTRAINT", "FULLTEXT", "SPATIAL", "FOREIGN", "CHECK"} {
+		if token.keyword(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// addConstraint 处理主键和索引，外键、CHECK等约束会被忽略
+func (t *table) addConstraint(def []sqlToken) {
+	i := 0
+	if def[0].keyword("CONSTRAINT") {
+		i++
+		if i < len(def) && def[i].name() && !isConstraint(def[i]) {
+			i++
+		}
+	}
+	if i >= len(def) {
+		return
+	}
+	switch {
+	case def[i].keyword("PRIMARY"):
+		t.setPrimaryKey(indexColumns(def[i:]))
+	case def[i].keyword("UNIQUE"), def[i].keyword("KEY"), def[i].keyword("INDEX"):
+		unique := def[i].keyword("UNIQUE")
+		i++
+		if unique && i < len(def) && (def[i].keyword("KEY") || def[i].keyword("INDEX")) {
+			i++
+		}
+		columns := indexColumns(def[i:])
+		if len(columns) == 0 {
+			return
+		}
+		// 没有索引名时MySQL使用第一列的列名
+		name := columns[0]
+		if i < len(def) && def[i].name() && !def[i].keyword("USING") {
+			name = def[i].text
+		}
+		t.addIndex(name, unique, columns)
+	}
+}
+
+// indexColumns 返回第一个括号中的列名，忽略前缀长度
+func indexColumns(def []sqlToken) []string {
+	output := make([]string, 0)
+	depth := 0
+	for _, token := range def {
+		switch {
+		case token.symbol("("):
+			depth++
+		case token.symbol(")"):
+			depth--
+			if depth == 0 {
+				return output
+			}
+		case depth == 1 && token.name():
+			output = append(output, token.text)
+		}
+	}
+	return output
+}
+
+func parseColumn(def []sqlToken) (column, error) {
+	if !def[0].name() {
+		return column{}, fmt.Errorf("第%d行: 期望列名, 实际为 %s", def[0].line, def[0].text)
+	}
+	if len(def) < 2 || def[1].kind != tokenIdent {
+		return column{}, fmt.Errorf("第%d行: 列%s缺少类型", def[0].line, def[0].text)
+	}
+	c := column{name: def[0].text}
+	dataType, args, unsigned := def[1].text, "", false
+	i := 2
+	if i < len(def) && def[i].symbol("(") {
+		end := skipParens(def, i)
+		args = joinTokens(def[i+1 : end-1])
+		i = end
+	}
+	for i < len(def) {
+		token := def[i]
+		i++
+		switch {
+		case token.keyword("UNSIGNED"):
+			unsigned = true
+		case token.keyword("NOT") && i < len(def) && def[i].keyword("NULL"):
+			c.notNull = true
+			i++
+		case token.keyword("AUTO_INCREMENT"):
+			c.autoIncrement = true
+		case token.keyword("PRIMARY") && i < len(def) && def[i].keyword("KEY"):
+			c.primaryKey = true
+			i++
+		case token.keyword("KEY"):
+			c.primaryKey = true
+		case token.keyword("UNIQUE"):
+			c.unique = true
+			if i < len(def) && def[i].keyword("KEY") {
+				i++
+			}
+		case token.keyword("DEFAULT"):
+			i = c.parseDefault(def, i)
+		case token.keyword("COMMENT") && i < len(def):
+			c.comment = def[i].text
+			i++
+		case token.keyword("ON") && i < len(def) && def[i].keyword("UPDATE"):
+			i += 2
+			if i < len(def) && def[i].symbol("(") {
+				i = skipParens(def, i)
+			}
+		case token.keyword("CHARACTER") && i < len(def) && def[i].keyword("SET"):
+			i += 2
+		case token.keyword("CHARSET"), token.keyword("COLLATE"):
+			i++
+		case token.symbol("("):
+			i = skipParens(def, i-1)
+		}
+	}
+	c.sqlType = mysqlColumnType(dataType, args, unsigned)
+	c.goType = mysqlGoType(dataType, args, unsigned)
+	return c, nil
+}
+
+// parseDefault 解析DEFAULT后的值，返回值之后的位置
+func (c *column) parseDefault(def []sqlToken, i int) int {
+	if i >= len(def) {
+		return i
+	}
+	token := def[i]
+	switch {
+	case token.keyword("NULL"):
+		return i + 1
+	case token.symbol("("):
+		end := skipParens(def, i)
+		c.hasDefault, c.defaultValue = true, joinTokens(def[i:end])
+		return end
+	case (token.symbol("-") || token.symbol("+")) && i+1 < len(def):
+		c.hasDefault, c.defaultValue = true, token.text+def[i+1].text
+		return i + 2
+	case token.kind == tokenIdent && i+1 < len(def) && def[i+1].symbol("("):
+		end := skipParens(def, i+1)
+		c.hasDefault, c.defaultValue = true, joinTokens(def[i:end])
+		return end
+	}
+	c.hasDefault, c.defaultValue = true, token.sql()
+	return i + 1
+}
+
+func mysqlColumnType(dataType, args string, unsigned bool) string {
+	output := strings.ToLower(dataType)
+	if args != "" {
+		output += "(" + args + ")"
+	}
+	if unsigned {
+		output += " unsigned"
+	}
+	return output
+}
+
+// mysqlGoType MySQL列类型转换为Go类型
+func mysqlGoType(dataType, args string, unsigned bool) string {
+	switch strings.ToLower(dataType) {
+	case "bool", "boolean":
+		return "bool"
+	case "tinyint":
+		if args == "1" {
+			return "bool"
+		}
+		return unsignedType("int8", unsigned)
+	case "smallint", "int2":
+		return unsignedType("int16", unsigned)
+	case "mediumint", "int", "integer", "int3", "int4":
+		return unsignedType("int", unsigned)
+	case "bigint", "int8", "serial":
+		return unsignedType("int64", unsigned)
+	case "float", "float4":
+		return "float32"
+	case "double", "real", "decimal", "numeric", "dec", "fixed", "float8":
+		return "float64"
+	case "bit":
+		if args == "" || args == "1" {
+			return "bool"
+		}
+		return "uint64"
+	case "year":
+		return "int"
+	case "date", "datetime", "timestamp":
+		return "time.Time"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "[]byte"
+	}
+	return "string"
+}
+
+func unsignedType(goType string, unsigned bool) string {
+	if unsigned {
+		return "u" + goType
+	}
+	return goType
+}
+
+// skipParens start为左括号的位置，返回匹配的右括号之后的位置
+func skipParens(tokens []sqlToken, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		if tokens[i].symbol("(") {
+			depth++
+		} else if tokens[i].symbol(")") {
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(tokens)
+}
+
+func joinTokens(tokens []sqlToken) string {
+	var sb strings.Builder
+	for i, token := range tokens {
+		if i > 0 && token.kind != tokenSymbol && tokens[i-1].kind != tokenSymbol {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(token.sql())
+	}
+	return sb.String()
+}
+
+// tokenize 将SQL切分为token，跳过注释
+func tokenize(sql string) ([]sqlToken, error) {
+	tokens := make([]sqlToken, 0)
+	line := 1
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "--") && (i+2 == len(sql) || sql[i+2] == ' ' || sql[i+2] == '\t' || sql[i+2] == '\n')):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("第%d行: 注释没有结束", line)
+			}
+			line += strings.Count(sql[i:i+2+end], "\n")
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			text, n, err := readQuoted(sql[i:], c)
+			if err != nil {
+				return nil, fmt.Errorf("第%d行: %w", line, err)
+			}
+			kind := tokenString
+			if c == '`' {
+				kind = tokenQuoted
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: text, line: line})
+			line += strings.Count(sql[i:i+n], "\n")
+			i += n
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(sql) && (sql[i] >= '0' && sql[i] <= '9' || sql[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: sql[start:i], line: line})
+		case isIdentByte(c):
+			start := i
+			for i < len(sql) && (isIdentByte(sql[i]) || sql[i] >= '0' && sql[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenIdent, text: sql[start:i], line: line})
+		default:
+			_, size := utf8.DecodeRuneInString(sql[i:])
+			tokens = append(tokens, sqlToken{kind: tokenSymbol, text: sql[i : i+size], line: line})
+			i += size
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= utf8.RuneSelf
+}
+
+// readQuoted 读取引号包裹的内容，支持重复引号和反斜杠转义，返回内容和消耗的字节数
+func readQuoted(s string, quote byte) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote && i+1 < len(s) && s[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+		case c == quote:
+			return sb.String(), i + 1, nil
+		case c == '\\' && quote != '`' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case '0':
+				sb.WriteByte(0)
+			default:
+				sb.WriteByte(s[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("引号%c没有结束", quote)
+}
@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+)
+
+func TestDDLToDSL(t *testing.T) {
+	ddl := "-- 用户\n" +
+		"CREATE TABLE IF NOT EXISTS `user_info` (\n" +
+		"  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'id',\n" +
+		"  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '昵称',\n" +
+		"  `isAdmin` tinyint(1) NOT NULL DEFAULT 0,\n" +
+		"  `score` decimal(10,2) DEFAULT NULL,\n" +
+		"  `ctime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uniq_name` (`user_name`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户表';\n" +
+		"INSERT INTO `user_info` VALUES (1, 'a', 0, 1.0, now());\n" +
+		"create table tag (name varchar(32) primary key)"
+
+	got, err := DDLToDSL(ddl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"// UserInfo 用户表\n" +
+		"// @table(name=user_info)\n" +
+		"type UserInfo struct {\n" +
+		"\tId       uint64    `gorm:\"type:bigint(20) unsigned;primary_key;AUTO_INCREMENT;not null\" json:\"id\" ego:\"primary_key\"` // id\n" +
+		"\tUserName string    `gorm:\"type:varchar(64);not null;default:'';unique_index:uniq_name\" json:\"userName\"`              // 昵称\n" +
+		"\tIsAdmin  bool      `gorm:\"column:isAdmin;type:tinyint(1);not null;default:0\" json:\"isAdmin\"`\n" +
+		"\tScore    float64   `gorm:\"type:decimal(10,2)\" json:\"score\"`\n" +
+		"\tCtime    time.Time `gorm:\"type:datetime;not null;default:CURRENT_TIMESTAMP\" json:\"ctime\"`\n" +
+		"}\n" +
+		"\n" +
+		"// @table(name=tag)\n" +
+		"type Tag struct {\n" +
+		"\tName string `gorm:\"type:varchar(32);primary_key\" json:\"name\" ego:\"primary_key\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if diagnostics := parser.CheckDSL(got); len(diagnostics) > 0 {
+		t.Fatalf("generated DSL is invalid: %v", diagnostics)
+	}
+
+	got, err = DDLToDSL("CREATE TABLE `order` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `uid` int NOT NULL,\n" +
+		"  `status` int NOT NULL,\n" +
+		"  CONSTRAINT `pk_order` PRIMARY KEY (`id`),\n" +
+		"  KEY `idx_uid_status` (`uid`, `status`),\n" +
+		"  INDEX (`status`)\n" +
+		")")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{
+		"`gorm:\"type:int;primary_key;not null\"",
+		"`gorm:\"type:int;not null;index:idx_uid_status\"",
+		"`gorm:\"type:int;not null;index:idx_uid_status;index:status\"",
+	} {
+		if !strings.Contains(got, tag) {
+			t.Fatalf("want %s in\n%s", tag, got)
+		}
+	}
+
+	_, err = DDLToDSL("CREATE TABLE `user` (\n  `id` int,\n  `name` varchar(10) COMMENT 'x\n")
+	if err == nil {
+		t.Fatal("want error for unterminated statement")
+	}
+}
+
+func TestDDLNameCollision(t *testing.T) {
+	got, err := DDLToDSL("CREATE TABLE y (user_id int, UserId int, userId int);\n" +
+		"CREATE TABLE user_info (id int);\n" +
+		"CREATE TABLE UserInfo (id int)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"// @table(name=y)\n" +
+		"type Y struct {\n" +
+		"\tUserId  int `gorm:\"type:int\" json:\"userId\"`\n" +
+		"\tUserId2 int `gorm:\"column:UserId;type:int\" json:\"userId2\"`\n" +
+		"\tUserId3 int `gorm:\"column:userId;type:int\" json:\"userId3\"`\n" +
+		"}\n" +
+		"\n" +
+		"// @table(name=user_info)\n" +
+		"type UserInfo struct {\n" +
+		"\tId int `gorm:\"type:int\" json:\"id\"`\n" +
+		"}\n" +
+		"\n" +
+		"// @table(name=UserInfo)\n" +
+		"type UserInfo2 struct {\n" +
+		"\tId int `gorm:\"type:int\" json:\"id\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if diagnostics := parser.CheckDSL(got); len(diagnostics) > 0 {
+		t.Fatalf("generated DSL is invalid: %v", diagnostics)
+	}
+}
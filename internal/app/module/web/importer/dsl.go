@@ -0,0 +1,124 @@
+package importer
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gotomicro/egoctl/internal/utils"
+)
+
+// Model 导入得到的模型，对应DSL中的一个结构体
+type Model struct {
+	Name    string
+	Table   string // 原始表名，写入 @table 注解
+	Comment string
+	Fields  []Field
+}
+
+// Field 模型字段
+type Field struct {
+	Name    string
+	Type    string
+	Tags    []Tag
+	Comment string // 写在字段行尾，ToModelInfos会转换为FieldComment
+}
+
+// Tag 字段的一个struct tag，多个值以;连接
+type Tag struct {
+	Key    string
+	Values []string
+}
+
+// AddTag 追加tag的值，同名tag已存在时合并
+func (f *Field) AddTag(key string, values ...string) {
+	for i := range f.Tags {
+		if f.Tags[i].Key == key {
+			f.Tags[i].Values = append(f.Tags[i].Values, values...)
+			return
+		}
+	}
+	f.Tags = append(f.Tags, Tag{Key: key, Values: values})
+}
+
+// Generate 将模型输出为egoctl的DSL
+func Generate(models []Model) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("package egoctl\n")
+	for _, model := range models {
+		sb.WriteString("\n")
+		if model.Comment != "" {
+			fmt.Fprintf(&sb, "// %s %s\n", model.Name, oneLine(model.Comment))
+		}
+		if model.Table != "" {
+			fmt.Fprintf(&sb, "// @table(name=%s)\n", model.Table)
+		}
+		fmt.Fprintf(&sb, "type %s struct {\n", model.Name)
+		for _, field := range model.Fields {
+			fmt.Fprintf(&sb, "\t%s %s", field.Name, field.Type)
+			if len(field.Tags) > 0 {
+				tags := make([]string, 0, len(field.Tags))
+				for _, tag := range field.Tags {
+					value := strings.ReplaceAll(strings.Join(tag.Values, ";"), "`", "")
+					tags = append(tags, tag.Key+":"+strconv.Quote(value))
+				}
+				fmt.Fprintf(&sb, " `%s`", strings.Join(tags, " "))
+			}
+			if field.Comment != "" {
+				fmt.Fprintf(&sb, " // %s", oneLine(field.Comment))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("}\n")
+	}
+	output, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", fmt.Errorf("格式化DSL失败, err: %w", err)
+	}
+	return string(output), nil
+}
+
+// Identifier 表名、列名转换为导出的Go标识符，非法字符替换为下划线后再转驼峰
+func Identifier(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "X"
+	}
+	if strings.ToUpper(name) == name {
+		name = strings.ToLower(name)
+	}
+	name = utils.CamelString(name)
+	if unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// JSONName 字段名转换为json tag使用的小驼峰
+func JSONName(fieldName string) string {
+	if fieldName == "" {
+		return fieldName
+	}
+	return strings.ToLower(fieldName[:1]) + fieldName[1:]
+}
+
+// NewField 创建列对应的字段，列名与字段名的蛇形不一致时写入gorm的column
+func NewField(name, column, goType string) Field {
+	field := Field{Name: name, Type: goType}
+	if utils.SnakeString(field.Name) != column {
+		field.AddTag("gorm", "column:"+column)
+	}
+	return field
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
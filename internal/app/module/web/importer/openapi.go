@@ -0,0 +1,408 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// InfoOpenAPI 从OpenAPI文档导入DSL的参数
+type InfoOpenAPI struct {
+	Content string `json:"content" binding:"required"` // JSON或YAML格式的OpenAPI 3、Swagger 2文档
+}
+
+// docMap 文档中的对象，保持key在文档中的顺序，生成的字段顺序与文档一致
+type docMap []docItem
+
+type docItem struct {
+	Key   string
+	Value interface{}
+}
+
+func (m docMap) get(key string) interface{} {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+func (m docMap) getMap(key string) docMap {
+	value, _ := m.get(key).(docMap)
+	return value
+}
+
+func (m docMap) getString(key string) string {
+	value, _ := m.get(key).(string)
+	return value
+}
+
+func (m docMap) getSlice(key string) []interface{} {
+	value, _ := m.get(key).([]interface{})
+	return value
+}
+
+// decodeDocument 解析JSON或YAML文档
+func decodeDocument(content []byte) (docMap, error) {
+	content = bytes.TrimSpace(content)
+	var value interface{}
+	if len(content) > 0 && content[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		v, err := decodeJSON(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("解析JSON失败, err: %w", err)
+		}
+		value = v
+	} else {
+		slice := yaml.MapSlice{}
+		if err := yaml.Unmarshal(content, &slice); err != nil {
+			return nil, fmt.Errorf("解析YAML失败, err: %w", err)
+		}
+		value = fromYAML(slice)
+	}
+	doc, ok := value.(docMap)
+	if !ok {
+		return nil, fmt.Errorf("文档不是对象")
+	}
+	return doc, nil
+}
+
+func decodeJSON(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		output := docMap{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, docItem{Key: fmt.Sprint(key), Value: value})
+		}
+		_, err = decoder.Token()
+		return output, err
+	case '[':
+		output := make([]interface{}, 0)
+		for decoder.More() {
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, value)
+		}
+		_, err = decoder.Token()
+		return output, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+func fromYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		output := make(docMap, 0, len(v))
+		for _, item := range v {
+			output = append(output, docItem{Key: fmt.Sprint(item.Key), Value: fromYAML(item.Value)})
+		}
+		return output
+	case []interface{}:
+		output := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			output = append(output, fromYAML(item))
+		}
+		return output
+	}
+	return value
+}
+
+// FromOpenAPI 将OpenAPI 3的components.schemas或Swagger 2的definitions中的对象转换为模型
+func FromOpenAPI(content []byte) ([]Model, error) {
+	doc, err := decodeDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	i := &openAPIImporter{
+		models:     make([]Model, 0),
+		modelNames: make(map[string]string),
+		usedNames:  make(map[string]bool),
+	}
+	switch {
+	case doc.get("openapi") != nil:
+		i.schemas = doc.getMap("components").getMap("schemas")
+		i.refPrefix = "#/components/schemas/"
+	case doc.get("swagger") != nil:
+		i.schemas = doc.getMap("definitions")
+		i.refPrefix = "#/definitions/"
+	default:
+		return nil, fmt.Errorf("不是OpenAPI 3或Swagger 2文档")
+	}
+	// 先确定所有schema的模型名，user_name与userName这类转换后同名的加数字后缀
+	for _, item := range i.schemas {
+		schema, ok := item.Value.(docMap)
+		if !ok || !isObjectSchema(schema) {
+			continue
+		}
+		i.modelNames[item.Key] = uniqueName(i.usedNames, Identifier(item.Key))
+	}
+	for _, item := range i.schemas {
+		schema, ok := item.Value.(docMap)
+		if !ok || !isObjectSchema(schema) {
+			continue
+		}
+		i.addModel(i.modelNames[item.Key], schema)
+	}
+	if len(i.models) == 0 {
+		return nil, fmt.Errorf("文档中没有对象类型的schema")
+	}
+	return i.models, nil
+}
+
+// OpenAPIToDSL 将OpenAPI文档转换为DSL
+func OpenAPIToDSL(content []byte) (string, error) {
+	models, err := FromOpenAPI(content)
+	if err != nil {
+		return "", err
+	}
+	return Generate(models)
+}
+
+type openAPIImporter struct {
+	schemas    docMap
+	refPrefix  string
+	models     []Model
+	modelNames map[string]string // schema名对应的模型名
+	usedNames  map[string]bool   // 已使用的模型名，包括内联对象
+}
+
+// uniqueName 名称已被使用时加上数字后缀，例如 UserAddress2
+func uniqueName(used map[string]bool, name string) string {
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
+
+// oneofValue 枚举值写入oneof，含空白的值用单引号括起来，含逗号、单引号的值无法表示
+func oneofValue(value string) (string, bool) {
+	if strings.ContainsAny(value, ",'") {
+		return "", false
+	}
+	if value == "" || strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+		return "'" + value + "'", true
+	}
+	return value, true
+}
+
+// addModel 添加模型，属性中内联的对象会以 模型名+字段名 添加到该模型之后，
+// 转换后同名的字段加数字后缀，json tag保持原属性名
+func (i *openAPIImporter) addModel(name string, schema docMap) {
+	model := Model{Name: name, Comment: schema.getString("description")}
+	index := len(i.models)
+	i.models = append(i.models, model)
+
+	properties, required := i.properties(schema, 0)
+	fieldNames := make(map[string]bool)
+	for _, property := range properties {
+		propertySchema, _ := property.Value.(docMap)
+		field := Field{Name: uniqueName(fieldNames, Identifier(property.Key))}
+		field.Type = i.goType(name+field.Name, propertySchema, 0)
+		field.AddTag("json", property.Key)
+		field.Comment = propertySchema.getString("description")
+
+		rules := make([]string, 0)
+		if required[property.Key] {
+			rules = append(rules, "required")
+		}
+		// 引用的枚举等非对象schema，约束写在被引用的schema上
+		constraint := i.resolveScalar(propertySchema)
+		if enum := constraint.getSlice("enum"); len(enum) > 0 {
+			values := make([]string, 0, len(enum))
+			for _, value := range enum {
+				if v, ok := oneofValue(fmt.Sprint(value)); ok {
+					values = append(values, v)
+				}
+			}
+			if len(values) == len(enum) {
+				rules = append(rules, "oneof="+strings.Join(values, " "))
+			} else {
+				// 有无法写入oneof的枚举值时不做校验，在注释中说明
+				field.Comment = strings.TrimSpace(field.Comment + " 枚举值含逗号或单引号, 未生成oneof校验")
+			}
+		}
+		if len(rules) > 0 {
+			field.AddTag("binding", strings.Join(rules, ","))
+		}
+		if format := constraint.getString("format"); format != "" {
+			field.AddTag("format", format)
+		}
+		if strings.EqualFold(property.Key, "id") {
+			field.AddTag("ego", "primary_key")
+		}
+		model.Fields = append(model.Fields, field)
+	}
+	i.models[index] = model
+}
+
+// properties 返回对象的属性和必填属性，allOf中的属性会合并进来
+func (i *openAPIImporter) properties(schema docMap, depth int) (docMap, map[string]bool) {
+	properties := docMap{}
+	required := make(map[string]bool)
+	if depth > 10 {
+		return properties, required
+	}
+	for _, item := range schema.getSlice("allOf") {
+		sub, ok := item.(docMap)
+		if !ok {
+			continue
+		}
+		if ref := sub.getString("$ref"); ref != "" {
+			sub = i.schemas.getMap(i.refName(ref))
+		}
+		subProperties, subRequired := i.properties(sub, depth+1)
+		properties = append(properties, subProperties...)
+		for key := range subRequired {
+			required[key] = true
+		}
+	}
+	properties = append(properties, schema.getMap("properties")...)
+	for _, key := range schema.getSlice("required") {
+		required[fmt.Sprint(key)] = true
+	}
+	return properties, required
+}
+
+// resolveScalar schema引用了非对象的schema时返回被引用的schema
+func (i *openAPIImporter) resolveScalar(schema docMap) docMap {
+	for depth := 0; depth < 10; depth++ {
+		ref := schema.getString("$ref")
+		if ref == "" {
+			return schema
+		}
+		target := i.schemas.getMap(i.refName(ref))
+		if target == nil || isObjectSchema(target) {
+			return schema
+		}
+		schema = target
+	}
+	return schema
+}
+
+func (i *openAPIImporter) refName(ref string) string {
+	if strings.HasPrefix(ref, i.refPrefix) {
+		return strings.TrimPrefix(ref, i.refPrefix)
+	}
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// goType schema转换为parseType支持的Go类型，name为内联对象使用的模型名
+func (i *openAPIImporter) goType(name string, schema docMap, depth int) string {
+	if depth > 10 {
+		return "interface{}"
+	}
+	ref := schema.getString("$ref")
+	if allOf := schema.getSlice("allOf"); ref == "" && len(allOf) == 1 && len(schema.getMap("properties")) == 0 {
+		sub, _ := allOf[0].(docMap)
+		ref = sub.getString("$ref")
+	}
+	if ref != "" {
+		refName := i.refName(ref)
+		target := i.schemas.getMap(refName)
+		if target != nil && !isObjectSchema(target) {
+			return i.goType(name, target, depth+1)
+		}
+		if modelName, ok := i.modelNames[refName]; ok {
+			return modelName
+		}
+		return Identifier(refName)
+	}
+
+	format := schema.getString("format")
+	switch schemaType(schema) {
+	case "string":
+		switch format {
+		case "date-time", "date":
+			return "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		switch format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case "number":
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		items := schema.getMap("items")
+		if items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + i.goType(name+"Item", items, depth+1)
+	case "object":
+		if isObjectSchema(schema) {
+			// 内联对象与schema或其他内联对象同名时加数字后缀
+			name = uniqueName(i.usedNames, name)
+			i.addModel(name, schema)
+			return name
+		}
+		if value := schema.getMap("additionalProperties"); value != nil {
+			return "map[string]" + i.goType(name+"Value", value, depth+1)
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// schemaType 返回schema的type，OpenAPI 3.1中type为数组时忽略null
+func schemaType(schema docMap) string {
+	switch v := schema.get("type").(type) {
+	case string:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	switch {
+	case schema.get("properties") != nil || schema.get("allOf") != nil:
+		return "object"
+	case schema.get("items") != nil:
+		return "array"
+	}
+	return ""
+}
+
+// isObjectSchema 有属性的对象才会转换为模型，只有additionalProperties的对象转换为map
+func isObjectSchema(schema docMap) bool {
+	return schemaType(schema) == "object" && (schema.get("properties") != nil || schema.get("allOf") != nil)
+}
@@ -0,0 +1,205 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+)
+
+func TestOpenAPIToDSL(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info:
+  title: demo
+  version: 1.0.0
+components:
+  schemas:
+    Status:
+      type: string
+      enum: [active, banned]
+    User:
+      description: 用户
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          format: int64
+        name:
+          type: string
+          description: 昵称
+        email:
+          type: string
+          format: email
+        status:
+          $ref: '#/components/schemas/Status'
+        createdAt:
+          type: string
+          format: date-time
+        tags:
+          type: array
+          items:
+            type: string
+        address:
+          type: object
+          properties:
+            city:
+              type: string
+        extra:
+          type: object
+          additionalProperties:
+            type: integer
+        orders:
+          type: array
+          items:
+            $ref: '#/components/schemas/Order'
+    Order:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          properties:
+            amount:
+              type: number
+    Base:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: integer
+`
+	got, err := OpenAPIToDSL([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"// User 用户\n" +
+		"type User struct {\n" +
+		"\tId        int64          `json:\"id\" binding:\"required\" format:\"int64\" ego:\"primary_key\"`\n" +
+		"\tName      string         `json:\"name\" binding:\"required\"` // 昵称\n" +
+		"\tEmail     string         `json:\"email\" format:\"email\"`\n" +
+		"\tStatus    string         `json:\"status\" binding:\"oneof=active banned\"`\n" +
+		"\tCreatedAt time.Time      `json:\"createdAt\" format:\"date-time\"`\n" +
+		"\tTags      []string       `json:\"tags\"`\n" +
+		"\tAddress   UserAddress    `json:\"address\"`\n" +
+		"\tExtra     map[string]int `json:\"extra\"`\n" +
+		"\tOrders    []Order        `json:\"orders\"`\n" +
+		"}\n" +
+		"\n" +
+		"type UserAddress struct {\n" +
+		"\tCity string `json:\"city\"`\n" +
+		"}\n" +
+		"\n" +
+		"type Order struct {\n" +
+		"\tId     int     `json:\"id\" binding:\"required\" ego:\"primary_key\"`\n" +
+		"\tAmount float64 `json:\"amount\"`\n" +
+		"}\n" +
+		"\n" +
+		"type Base struct {\n" +
+		"\tId int `json:\"id\" binding:\"required\" ego:\"primary_key\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if diagnostics := parser.CheckDSL(got); len(diagnostics) > 0 {
+		t.Fatalf("generated DSL is invalid: %v", diagnostics)
+	}
+
+	swagger := `{"swagger": "2.0", "definitions": {"Pet": {"type": "object", "properties": {"kind": {"type": "string", "enum": ["cat", "dog"]}, "weight": {"type": "number", "format": "float"}}}}}`
+	got, err = OpenAPIToDSL([]byte(swagger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "package egoctl\n" +
+		"\n" +
+		"type Pet struct {\n" +
+		"\tKind   string  `json:\"kind\" binding:\"oneof=cat dog\"`\n" +
+		"\tWeight float32 `json:\"weight\" format:\"float\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestOpenAPINameCollision(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+components:
+  schemas:
+    user_name:
+      type: object
+      properties:
+        id:
+          type: integer
+    userName:
+      type: object
+      properties:
+        ref:
+          $ref: '#/components/schemas/userName'
+    User:
+      type: object
+      properties:
+        user_name:
+          type: string
+        userName:
+          type: string
+          enum: [new user, vip, '']
+        address:
+          type: object
+          properties:
+            city:
+              type: string
+    UserAddress:
+      type: object
+      properties:
+        zip:
+          type: string
+`
+	got, err := OpenAPIToDSL([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"type UserName struct {\n" +
+		"\tId int `json:\"id\" ego:\"primary_key\"`\n" +
+		"}\n" +
+		"\n" +
+		"type UserName2 struct {\n" +
+		"\tRef UserName2 `json:\"ref\"`\n" +
+		"}\n" +
+		"\n" +
+		"type User struct {\n" +
+		"\tUserName  string       `json:\"user_name\"`\n" +
+		"\tUserName2 string       `json:\"userName\" binding:\"oneof='new user' vip ''\"`\n" +
+		"\tAddress   UserAddress2 `json:\"address\"`\n" +
+		"}\n" +
+		"\n" +
+		"type UserAddress2 struct {\n" +
+		"\tCity string `json:\"city\"`\n" +
+		"}\n" +
+		"\n" +
+		"type UserAddress struct {\n" +
+		"\tZip string `json:\"zip\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// 含逗号的枚举值不生成oneof，其他字段正常转换
+	doc = `{"swagger": "2.0", "definitions": {"Pet": {"type": "object", "required": ["kind"], "properties": {"kind": {"type": "string", "description": "种类", "enum": ["cat,dog", "fish"]}, "name": {"type": "string", "enum": ["a'b"]}, "size": {"type": "string", "enum": ["s", "m"]}}}}}`
+	got, err = OpenAPIToDSL([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "package egoctl\n" +
+		"\n" +
+		"type Pet struct {\n" +
+		"\tKind string `json:\"kind\" binding:\"required\"` // 种类 枚举值含逗号或单引号, 未生成oneof校验\n" +
+		"\tName string `json:\"name\"`                    // 枚举值含逗号或单引号, 未生成oneof校验\n" +
+		"\tSize string `json:\"size\" binding:\"oneof=s m\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,578 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// protoScalarTypes proto标量类型对应的Go类型
+var protoScalarTypes = map[string]string{
+	"double":   "float64",
+	"float":    "float32",
+	"int32":    "int32",
+	"sint32":   "int32",
+	"sfixed32": "int32",
+	"int64":    "int64",
+	"sint64":   "int64",
+	"sfixed64": "int64",
+	"uint32":   "uint32",
+	"fixed32":  "uint32",
+	"uint64":   "uint64",
+	"fixed64":  "uint64",
+	"bool":     "bool",
+	"string":   "string",
+	"bytes":    "[]byte",
+}
+
+// protoWellKnownTypes google.protobuf中常用的类型，wrapper类型转换为指针以保留null
+var protoWellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp":   "time.Time",
+	"google.protobuf.Duration":    "int64",
+	"google.protobuf.Any":         "interface{}",
+	"google.protobuf.Value":       "interface{}",
+	"google.protobuf.Struct":      "map[string]interface{}",
+	"google.protobuf.ListValue":   "[]interface{}",
+	"google.protobuf.DoubleValue": "*float64",
+	"google.protobuf.FloatValue":  "*float32",
+	"google.protobuf.Int64Value":  "*int64",
+	"google.protobuf.UInt64Value": "*uint64",
+	"google.protobuf.Int32Value":  "*int32",
+	"google.protobuf.UInt32Value": "*uint32",
+	"google.protobuf.BoolValue":   "*bool",
+	"google.protobuf.StringValue": "*string",
+	"google.protobuf.BytesValue":  "[]byte",
+}
+
+type protoToken struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// protoComment 注释，ownLine表示注释前没有其他代码，可以作为下一行定义的注释
+type protoComment struct {
+	text    string
+	ownLine bool
+}
+
+type protoMessage struct {
+	fullName string // 包含package和外层message，例如 demo.User.Address
+	pkg      string
+	goName   string // 嵌套的message拼接外层名称，例如 UserAddress
+	comment  string
+	fields   []protoField
+}
+
+type protoField struct {
+	name     string
+	typ      string
+	repeated bool
+	mapKey   string
+	jsonName string
+	comment  string
+	line     int
+}
+
+// protoSet 多个proto文件中的message和enum，类型可以跨文件引用
+type protoSet struct {
+	messages []*protoMessage
+	names    map[string]*protoMessage
+	enums    map[string]bool
+}
+
+// FromProto 解析proto文件内容，将message转换为模型，enum转换为int32
+func FromProto(contents ...string) ([]Model, error) {
+	set := &protoSet{names: make(map[string]*protoMessage), enums: make(map[string]bool)}
+	for _, content := range contents {
+		tokens, comments, err := tokenizeProto(content)
+		if err != nil {
+			return nil, err
+		}
+		p := &protoParser{tokens: tokens, comments: comments, set: set}
+		if err = p.parseFile(); err != nil {
+			return nil, err
+		}
+	}
+	if len(set.messages) == 0 {
+		return nil, fmt.Errorf("未找到message定义")
+	}
+	if err := set.resolveNames(); err != nil {
+		return nil, err
+	}
+
+	models := make([]Model, 0, len(set.messages))
+	for _, message := range set.messages {
+		model := Model{Name: message.goName, Comment: message.comment}
+		fieldNames := make(map[string]bool)
+		for _, f := range message.fields {
+			goType, err := set.fieldType(message.fullName, f)
+			if err != nil {
+				return nil, fmt.Errorf("第%d行: %w", f.line, err)
+			}
+			// 转换后同名的字段加数字后缀，json tag保持原字段名
+			field := Field{Name: uniqueName(fieldNames, Identifier(f.name)), Type: goType, Comment: f.comment}
+			jsonName := f.jsonName
+			if jsonName == "" && field.Name != Identifier(f.name) {
+				jsonName = f.name
+			}
+			if jsonName == "" {
+				jsonName = JSONName(field.Name)
+			}
+			field.AddTag("json", jsonName)
+			if strings.EqualFold(f.name, "id") {
+				field.AddTag("ego", "primary_key")
+			}
+			model.Fields = append(model.Fields, field)
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+// ProtoToDSL 将proto文件转换为DSL
+func ProtoToDSL(contents ...string) (string, error) {
+	models, err := FromProto(contents...)
+	if err != nil {
+		return "", err
+	}
+	return Generate(models)
+}
+
+// resolveNames 不同package中模型名相同的message，模型名加上package前缀，例如 ShopV1User、ShopV1UserAddress，
+// 加上前缀后仍然重名时返回错误
+func (s *protoSet) resolveNames() error {
+	count := make(map[string]int)
+	for _, message := range s.messages {
+		count[message.goName]++
+	}
+	// 外层message加了前缀时，嵌套的message也加上，message按定义顺序排列，外层在前
+	prefixed := make(map[string]bool)
+	for _, message := range s.messages {
+		parent := message.fullName[:strings.LastIndex(message.fullName, ".")+1]
+		if message.pkg != "" && (count[message.goName] > 1 || prefixed[strings.TrimSuffix(parent, ".")]) {
+			message.goName = Identifier(message.pkg) + message.goName
+			prefixed[message.fullName] = true
+		}
+	}
+	names := make(map[string]*protoMessage)
+	for _, message := range s.messages {
+		if other, ok := names[message.goName]; ok {
+			return fmt.Errorf("message %s与%s的模型名都是%s", other.fullName, message.fullName, message.goName)
+		}
+		names[message.goName] = message
+	}
+	return nil
+}
+
+func (s *protoSet) fieldType(scope string, f protoField) (string, error) {
+	value, err := s.goType(scope, f.typ)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case f.mapKey != "":
+		key, ok := protoScalarTypes[f.mapKey]
+		if !ok || key == "[]byte" {
+			return "", fmt.Errorf("map的key不支持类型%s", f.mapKey)
+		}
+		return "map[" + key + "]" + strings.TrimPrefix(value, "*"), nil
+	case f.repeated:
+		return "[]" + strings.TrimPrefix(value, "*"), nil
+	}
+	return value, nil
+}
+
+// goType 按proto的作用域规则从内向外查找类型，message返回指针
+func (s *protoSet) goType(scope, typ string) (string, error) {
+	if goType, ok := protoScalarTypes[typ]; ok {
+		return goType, nil
+	}
+	candidates := make([]string, 0)
+	if strings.HasPrefix(typ, ".") {
+		candidates = append(candidates, typ[1:])
+	} else {
+		for {
+			if scope == "" {
+				candidates = append(candidates, typ)
+				break
+			}
+			candidates = append(candidates, scope+"."+typ)
+			if i := strings.LastIndex(scope, "."); i >= 0 {
+				scope = scope[:i]
+			} else {
+				scope = ""
+			}
+		}
+	}
+	for _, name := range candidates {
+		if message, ok := s.names[name]; ok {
+			return "*" + message.goName, nil
+		}
+		if s.enums[name] {
+			return "int32", nil
+		}
+		if goType, ok := protoWellKnownTypes[name]; ok {
+			return goType, nil
+		}
+	}
+	return "", fmt.Errorf("未知类型%s，请同时传入定义它的proto文件", typ)
+}
+
+type protoParser struct {
+	tokens   []protoToken
+	pos      int
+	comments map[int]protoComment
+	set      *protoSet
+	pkg      string // 当前文件的package
+}
+
+func (p *protoParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *protoParser) peek() protoToken {
+	if p.eof() {
+		line := 0
+		if len(p.tokens) > 0 {
+			line = p.tokens[len(p.tokens)-1].line
+		}
+		return protoToken{kind: tokenSymbol, text: "EOF", line: line}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *protoParser) next() protoToken {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *protoParser) expect(text string) error {
+	token := p.next()
+	if token.text != text {
+		return fmt.Errorf("第%d行: 期望 %s, 实际为 %s", token.line, text, token.text)
+	}
+	return nil
+}
+
+func (p *protoParser) expectIdent() (protoToken, error) {
+	token := p.next()
+	if token.kind != tokenIdent {
+		return token, fmt.Errorf("第%d行: 期望名称, 实际为 %s", token.line, token.text)
+	}
+	return token, nil
+}
+
+// skipStatement 跳过到分号为止的语句，其中的花括号会整体跳过
+func (p *protoParser) skipStatement() {
+	for !p.eof() {
+		token := p.next()
+		if token.text == ";" {
+			return
+		}
+		if token.text == "{" {
+			p.pos--
+			p.skipBlock()
+			return
+		}
+	}
+}
+
+// skipBlock 跳过下一个花括号包裹的内容
+func (p *protoParser) skipBlock() {
+	depth := 0
+	for !p.eof() {
+		token := p.next()
+		switch token.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// leadingComment 返回紧挨着line上方的注释
+func (p *protoParser) leadingComment(line int) string {
+	lines := make([]string, 0)
+	for l := line - 1; l > 0; l-- {
+		comment, ok := p.comments[l]
+		if !ok || !comment.ownLine {
+			break
+		}
+		lines = append([]string{comment.text}, lines...)
+	}
+	return strings.Join(lines, " ")
+}
+
+// fieldComment 优先使用行尾注释
+func (p *protoParser) fieldComment(startLine, endLine int) string {
+	if comment, ok := p.comments[endLine]; ok && !comment.ownLine {
+		return comment.text
+	}
+	return p.leadingComment(startLine)
+}
+
+func (p *protoParser) parseFile() error {
+	pkg := ""
+	for !p.eof() {
+		token := p.next()
+		switch token.text {
+		case "package":
+			name, err := p.expectIdent()
+			if err != nil {
+				return err
+			}
+			pkg = name.text
+			p.pkg = pkg
+			p.skipStatement()
+		case "syntax", "edition", "import", "option":
+			p.skipStatement()
+		case "message":
+			if err := p.parseMessage(pkg, "", token.line); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(pkg); err != nil {
+				return err
+			}
+		case "service", "extend":
+			p.skipBlock()
+		case ";":
+		default:
+			return fmt.Errorf("第%d行: 不支持的定义 %s", token.line, token.text)
+		}
+	}
+	return nil
+}
+
+func (p *protoParser) parseEnum(scope string) error {
+	name, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	p.set.enums[joinScope(scope, name.text)] = true
+	p.skipBlock()
+	return nil
+}
+
+func (p *protoParser) parseMessage(scope, parentGoName string, line int) error {
+	name, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	message := &protoMessage{
+		fullName: joinScope(scope, name.text),
+		pkg:      p.pkg,
+		goName:   parentGoName + Identifier(name.text),
+		comment:  p.leadingComment(line),
+	}
+	p.set.messages = append(p.set.messages, message)
+	p.set.names[message.fullName] = message
+	if err = p.expect("{"); err != nil {
+		return err
+	}
+	return p.parseBody(message, "}")
+}
+
+// parseBody 解析message或oneof的内容，直到end
+func (p *protoParser) parseBody(message *protoMessage, end string) error {
+	for {
+		token := p.peek()
+		switch token.text {
+		case end:
+			p.pos++
+			return nil
+		case "EOF":
+			return fmt.Errorf("第%d行: message %s没有结束", token.line, message.fullName)
+		case "message":
+			p.pos++
+			if err := p.parseMessage(message.fullName, message.goName, token.line); err != nil {
+				return err
+			}
+		case "enum":
+			p.pos++
+			if err := p.parseEnum(message.fullName); err != nil {
+				return err
+			}
+		case "oneof":
+			p.pos++
+			if _, err := p.expectIdent(); err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseBody(message, "}"); err != nil {
+				return err
+			}
+		case "option", "reserved", "extensions":
+			p.skipStatement()
+		case "extend":
+			p.skipBlock()
+		case ";":
+			p.pos++
+		default:
+			field, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			message.fields = append(message.fields, field)
+		}
+	}
+}
+
+// parseField 解析 [repeated|optional|required] type name = number [options]; 或 map<K, V> name = number;
+func (p *protoParser) parseField() (protoField, error) {
+	start := p.peek()
+	field := protoField{line: start.line}
+	switch start.text {
+	case "repeated":
+		field.repeated = true
+		p.pos++
+	case "optional", "required":
+		p.pos++
+	case "group":
+		return field, fmt.Errorf("第%d行: 不支持group", start.line)
+	}
+	typ, err := p.expectIdent()
+	if err != nil {
+		return field, err
+	}
+	field.typ = typ.text
+	if typ.text == "map" && p.peek().text == "<" {
+		p.pos++
+		key, err := p.expectIdent()
+		if err != nil {
+			return field, err
+		}
+		if err = p.expect(","); err != nil {
+			return field, err
+		}
+		value, err := p.expectIdent()
+		if err != nil {
+			return field, err
+		}
+		if err = p.expect(">"); err != nil {
+			return field, err
+		}
+		field.mapKey, field.typ = key.text, value.text
+	}
+	name, err := p.expectIdent()
+	if err != nil {
+		return field, err
+	}
+	field.name = name.text
+	if err = p.expect("="); err != nil {
+		return field, err
+	}
+	p.next()
+	if p.peek().text == "[" {
+		for !p.eof() && p.peek().text != "]" {
+			token := p.next()
+			if token.text == "json_name" && p.peek().text == "=" {
+				p.pos++
+				field.jsonName = p.next().text
+			}
+		}
+		if err = p.expect("]"); err != nil {
+			return field, err
+		}
+	}
+	endLine := p.peek().line
+	if err = p.expect(";"); err != nil {
+		return field, err
+	}
+	field.comment = p.fieldComment(start.line, endLine)
+	return field, nil
+}
+
+func joinScope(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// tokenizeProto 将proto切分为token，注释按所在行单独记录
+func tokenizeProto(content string) ([]protoToken, map[int]protoComment, error) {
+	tokens := make([]protoToken, 0)
+	comments := make(map[int]protoComment)
+	line := 1
+	lastTokenLine := 0
+	addComment := func(commentLine int, text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		comments[commentLine] = protoComment{text: text, ownLine: lastTokenLine != commentLine}
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			addComment(line, content[i+2:i+end])
+			i += end
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return nil, nil, fmt.Errorf("第%d行: 注释没有结束", line)
+			}
+			text := content[i+2 : i+2+end]
+			startLine := line
+			line += strings.Count(text, "\n")
+			parts := make([]string, 0)
+			for _, l := range strings.Split(text, "\n") {
+				if l = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), "*")); l != "" {
+					parts = append(parts, l)
+				}
+			}
+			// 多行注释记录在结束的行，作为下一行定义的注释
+			if len(parts) > 0 {
+				comments[line] = protoComment{text: strings.Join(parts, " "), ownLine: lastTokenLine != startLine}
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			text, n, err := readQuoted(content[i:], c)
+			if err != nil {
+				return nil, nil, fmt.Errorf("第%d行: %w", line, err)
+			}
+			tokens = append(tokens, protoToken{kind: tokenString, text: text, line: line})
+			lastTokenLine = line
+			i += n
+		case isProtoIdentByte(c) || c >= '0' && c <= '9' || c == '-' || c == '+':
+			start := i
+			i++
+			for i < len(content) && (isProtoIdentByte(content[i]) || content[i] >= '0' && content[i] <= '9') {
+				i++
+			}
+			kind := tokenIdent
+			if !isProtoIdentByte(c) || c == '.' && i > start+1 && content[start+1] >= '0' && content[start+1] <= '9' {
+				kind = tokenNumber
+			}
+			tokens = append(tokens, protoToken{kind: kind, text: content[start:i], line: line})
+			lastTokenLine = line
+		default:
+			tokens = append(tokens, protoToken{kind: tokenSymbol, text: string(c), line: line})
+			lastTokenLine = line
+			i++
+		}
+	}
+	return tokens, comments, nil
+}
+
+func isProtoIdentByte(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
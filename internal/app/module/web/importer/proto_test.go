@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+)
+
+func TestProtoToDSL(t *testing.T) {
+	user := `syntax = "proto3";
+
+package demo.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "demo/v1;v1";
+
+// 用户
+message User {
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_ACTIVE = 1;
+  }
+  message Address {
+    string city = 1;
+  }
+
+  int64 id = 1;
+  string user_name = 2 [json_name = "name"]; // 昵称
+  // 状态
+  Status status = 3;
+  repeated string tags = 4;
+  map<string, int32> scores = 5;
+  Address address = 6;
+  google.protobuf.Timestamp created_at = 7;
+  oneof contact {
+    string email = 8;
+    string phone = 9;
+  }
+  repeated demo.v1.Order orders = 10;
+  reserved 11;
+}
+
+service UserService {
+  rpc Get(User) returns (User) {}
+}
+`
+	order := `syntax = "proto3";
+package demo.v1;
+
+message Order {
+  int64 id = 1;
+  User.Address address = 2;
+}
+`
+	got, err := ProtoToDSL(user, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"// User 用户\n" +
+		"type User struct {\n" +
+		"\tId        int64            `json:\"id\" ego:\"primary_key\"`\n" +
+		"\tUserName  string           `json:\"name\"`   // 昵称\n" +
+		"\tStatus    int32            `json:\"status\"` // 状态\n" +
+		"\tTags      []string         `json:\"tags\"`\n" +
+		"\tScores    map[string]int32 `json:\"scores\"`\n" +
+		"\tAddress   *UserAddress     `json:\"address\"`\n" +
+		"\tCreatedAt time.Time        `json:\"createdAt\"`\n" +
+		"\tEmail     string           `json:\"email\"`\n" +
+		"\tPhone     string           `json:\"phone\"`\n" +
+		"\tOrders    []Order          `json:\"orders\"`\n" +
+		"}\n" +
+		"\n" +
+		"type UserAddress struct {\n" +
+		"\tCity string `json:\"city\"`\n" +
+		"}\n" +
+		"\n" +
+		"type Order struct {\n" +
+		"\tId      int64        `json:\"id\" ego:\"primary_key\"`\n" +
+		"\tAddress *UserAddress `json:\"address\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if diagnostics := parser.CheckDSL(got); len(diagnostics) > 0 {
+		t.Fatalf("generated DSL is invalid: %v", diagnostics)
+	}
+
+	_, err = ProtoToDSL(`syntax = "proto3"; message A { Missing b = 1; }`)
+	if err == nil {
+		t.Fatal("want error for unknown type")
+	}
+}
+
+func TestProtoNameCollision(t *testing.T) {
+	demo := `syntax = "proto3";
+package demo.v1;
+
+message User {
+  int64 id = 1;
+  shop.v1.User seller = 2;
+}
+`
+	shop := `syntax = "proto3";
+package shop.v1;
+
+message User {
+  message Address {
+    string city = 1;
+  }
+  int64 id = 1;
+  Address address = 2;
+}
+`
+	got, err := ProtoToDSL(demo, shop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package egoctl\n" +
+		"\n" +
+		"type DemoV1User struct {\n" +
+		"\tId     int64       `json:\"id\" ego:\"primary_key\"`\n" +
+		"\tSeller *ShopV1User `json:\"seller\"`\n" +
+		"}\n" +
+		"\n" +
+		"type ShopV1User struct {\n" +
+		"\tId      int64              `json:\"id\" ego:\"primary_key\"`\n" +
+		"\tAddress *ShopV1UserAddress `json:\"address\"`\n" +
+		"}\n" +
+		"\n" +
+		"type ShopV1UserAddress struct {\n" +
+		"\tCity string `json:\"city\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// 转换后同名的字段
+	got, err = ProtoToDSL(`syntax = "proto3";
+message Order {
+  int64 user_id = 1;
+  int64 UserId = 2;
+  int64 userId = 3 [json_name = "uid"];
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "package egoctl\n" +
+		"\n" +
+		"type Order struct {\n" +
+		"\tUserId  int64 `json:\"userId\"`\n" +
+		"\tUserId2 int64 `json:\"UserId\"`\n" +
+		"\tUserId3 int64 `json:\"uid\"`\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// 同一package中嵌套message拼接后的名称与其他message重名
+	_, err = ProtoToDSL(`syntax = "proto3"; package demo; message User { message Address {} } message UserAddress {}`)
+	if err == nil {
+		t.Fatal("want error for nested message colliding with top-level message")
+	}
+
+	_, err = ProtoToDSL(`syntax = "proto3"; message A { int32 a = 1; }`, `syntax = "proto3"; message A { int32 b = 1; }`)
+	if err == nil {
+		t.Fatal("want error for duplicate message without package")
+	}
+}
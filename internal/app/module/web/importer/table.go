@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"strings"
+)
+
+// column 数据库中的一列，DDL和数据库的导入都先转换为column
+type column struct {
+	name          string
+	sqlType       string // 写入gorm的type
+	goType        string
+	notNull       bool
+	autoIncrement bool
+	primaryKey    bool
+	unique        bool
+	hasDefault    bool
+	defaultValue  string
+	indexes       []string // gorm的index、unique_index
+	comment       string
+}
+
+// table 数据库中的一张表
+type table struct {
+	name    string
+	comment string
+	columns []column
+}
+
+// addIndex 给索引包含的列加上gorm的index tag，同名的index即为联合索引
+func (t *table) addIndex(name string, unique bool, columns []string) {
+	for _, columnName := range columns {
+		for i := range t.columns {
+			if !strings.EqualFold(t.columns[i].name, columnName) {
+				continue
+			}
+			if unique {
+				t.columns[i].indexes = append(t.columns[i].indexes, "unique_index:"+name)
+			} else {
+				t.columns[i].indexes = append(t.columns[i].indexes, "index:"+name)
+			}
+		}
+	}
+}
+
+func (t *table) setPrimaryKey(columns []string) {
+	for _, columnName := range columns {
+		for i := range t.columns {
+			if strings.EqualFold(t.columns[i].name, columnName) {
+				t.columns[i].primaryKey = true
+			}
+		}
+	}
+}
+
+// model 转换为模型，used为已使用的模型名称，只有大小写或下划线不同的表名和列名加上数字后缀区分
+func (t table) model(used map[string]bool) Model {
+	model := Model{Name: uniqueName(used, Identifier(t.name)), Table: t.name, Comment: t.comment}
+	fieldNames := make(map[string]bool)
+	for _, c := range t.columns {
+		model.Fields = append(model.Fields, c.field(uniqueName(fieldNames, Identifier(c.name))))
+	}
+	return model
+}
+
+func (c column) field(name string) Field {
+	field := NewField(name, c.name, c.goType)
+	if c.sqlType != "" {
+		field.AddTag("gorm", "type:"+c.sqlType)
+	}
+	if c.primaryKey {
+		field.AddTag("gorm", "primary_key")
+	}
+	if c.autoIncrement {
+		field.AddTag("gorm", "AUTO_INCREMENT")
+	}
+	if c.notNull {
+		field.AddTag("gorm", "not null")
+	}
+	if c.unique {
+		field.AddTag("gorm", "unique")
+	}
+	if c.hasDefault {
+		field.AddTag("gorm", "default:"+c.defaultValue)
+	}
+	if len(c.indexes) > 0 {
+		field.AddTag("gorm", c.indexes...)
+	}
+	field.AddTag("json", JSONName(field.Name))
+	if c.primaryKey {
+		field.AddTag("ego", "primary_key")
+	}
+	field.Comment = c.comment
+	return field
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// collectTypeDocs 记录每个类型的注释，单个类型声明的注释在GenDecl上
+func (a *astParser) collectTypeDocs(f *ast.File) {
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			if doc != nil {
+				a.typeDocs[typeSpec.Name.Name] = doc
+			}
+		}
+	}
+}
+
+// parseAnnotations 解析注释中的注解，例如 // @table(name=users, softDelete=true)
+func (a *astParser) parseAnnotations(cg *ast.CommentGroup) []SpecAnnotation {
+	output := make([]SpecAnnotation, 0)
+	if cg == nil {
+		return output
+	}
+	for _, comment := range cg.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(text, "@") {
+			continue
+		}
+		annotation, err := parseAnnotation(text)
+		if err != nil {
+			a.addDiagnostic(comment.Pos(), err.Error())
+			continue
+		}
+		output = append(output, annotation)
+	}
+	return output
+}
+
+// parseAnnotation 解析单个注解，支持 @name、@name value、@name(value)、@name(key=value, key2=value2)
+func parseAnnotation(text string) (SpecAnnotation, error) {
+	text = strings.TrimPrefix(text, "@")
+	i := 0
+	for i < len(text) {
+		r := rune(text[i])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+			break
+		}
+		i++
+	}
+	name := text[:i]
+	if name == "" {
+		return SpecAnnotation{}, fmt.Errorf("annotation name is empty, expr is @%s", text)
+	}
+
+	annotation := SpecAnnotation{
+		Name:       name,
+		Properties: make(map[string]string),
+	}
+	rest := strings.TrimSpace(text[i:])
+	if !strings.HasPrefix(rest, "(") {
+		annotation.Value = trimQuote(rest)
+		return annotation, nil
+	}
+	end := strings.LastIndex(rest, ")")
+	if end < 0 {
+		return SpecAnnotation{}, fmt.Errorf("annotation @%s missing ')'", name)
+	}
+	parts, err := splitProperties(rest[1:end])
+	if err != nil {
+		return SpecAnnotation{}, fmt.Errorf("annotation @%s %w", name, err)
+	}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		// 以引号开头的是值，值中可以有=
+		if len(kv) == 1 || part[0] == '"' || part[0] == '\'' {
+			annotation.Value = trimQuote(part)
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			return SpecAnnotation{}, fmt.Errorf("annotation @%s has an empty property name", name)
+		}
+		annotation.Properties[key] = trimQuote(strings.TrimSpace(kv[1]))
+	}
+	return annotation, nil
+}
+
+// splitProperties 按逗号分隔注解的属性，引号中的逗号不分隔
+func splitProperties(text string) ([]string, error) {
+	parts := make([]string, 0)
+	var quote byte
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("has an unterminated quote")
+	}
+	return append(parts, text[start:]), nil
+}
+
+func trimQuote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// annotationMap 以注解名称为key，方便模板中使用，例如 modelAnnotations.table.Properties.name。
+// 同名的注解以最后一个为准，可以重复的注解（例如多个@index）在模板中使用按顺序排列的注解列表
+func annotationMap(annotations []SpecAnnotation) map[string]SpecAnnotation {
+	output := make(map[string]SpecAnnotation, len(annotations))
+	for _, annotation := range annotations {
+		output[annotation.Name] = annotation
+	}
+	return output
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	EgoctlDir   = ".egoctl" // 项目内egoctl生成的元数据目录
+	BaselineDir = "base"    // 上一次生成的原始内容，用于三方合并
+)
+
+// baselinePath 获取目标文件对应的基线文件路径，不在项目目录下的文件不记录基线
+func baselinePath(projectPath string, filename string) (string, bool) {
+	if projectPath == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(projectPath, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.Join(projectPath, EgoctlDir, BaselineDir, rel), true
+}
+
+// readBaseline 读取上一次生成的原始内容
+func readBaseline(projectPath string, filename string) ([]byte, bool) {
+	basePath, ok := baselinePath(projectPath, filename)
+	if !ok {
+		return nil, false
+	}
+	content, err := ioutil.ReadFile(basePath)
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// writeBaseline 记录本次生成的原始内容
+func writeBaseline(projectPath string, filename string, content []byte) error {
+	basePath, ok := baselinePath(projectPath, filename)
+	if !ok {
+		return nil
+	}
+	err := createPath(filepath.Dir(basePath))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(basePath, content, 0644)
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
 	"github.com/gotomicro/egoctl/internal/system"
 	"github.com/gotomicro/egoctl/internal/utils"
-	"github.com/pelletier/go-toml"
 )
 
 const (
@@ -23,7 +22,8 @@ func NewParser(option UserOption) *Container {
 		TmplOption:       TmplOption{},
 		CurPath:          system.CurrentDir,
 		EnableModules:    make(map[string]interface{}), // get the user configuration, get the enable module result
-		FunctionOnce:     make(map[string]sync.Once),   // get the tmpl configuration, get the function once result
+		FunctionOnce:     make(map[string]*sync.Once),  // get the tmpl configuration, get the function once result
+		Changes:          make([]FileChange, 0),
 		StoreData: StoreData{
 			UserOption: option,
 		},
@@ -37,6 +37,8 @@ func (c *Container) Run() error {
 	c.initTemplateOption()
 	c.initParser()
 	c.initRender()
+	c.pruneFiles()
+	c.writeManifest()
 	return c.err
 }
 
@@ -47,8 +49,6 @@ func (c *Container) initUserOption() {
 		c.err = fmt.Errorf("请在%s目录下创建go.mod文件", c.UserOption.ProjectPath)
 		return
 	}
-	c.EnableModules["*"] = struct{}{}
-	c.StoreData.EnableModules = c.EnableModules
 }
 
 // 解析模板配置
@@ -56,23 +56,30 @@ func (c *Container) initTemplateOption() {
 	if c.err != nil {
 		return
 	}
-	tree, err := toml.LoadFile(c.UserOption.GitLocalPath + "/" + c.UserOption.ProType + "/egoctl.toml")
-	if err != nil {
-		c.err = fmt.Errorf("egoctl tmpl exec error, err: %w", err)
+	c.TmplOption, c.err = LoadTmplOption(c.UserOption.GitLocalPath, c.UserOption.ProType)
+	if c.err != nil {
 		return
 	}
+	c.StoreData.TemplateOption = c.TmplOption
 
-	err = tree.Unmarshal(&c.TmplOption)
-	if err != nil {
-		c.err = fmt.Errorf("egoctl tmpl parse error, err: %w", err)
+	// 只生成开启的模块及其依赖的模块
+	c.EnableModules, c.err = c.TmplOption.ResolveModules(c.UserOption.EnableModule)
+	if c.err != nil {
 		return
 	}
+	c.StoreData.EnableModules = c.EnableModules
 
-	c.StoreData.TemplateOption = c.TmplOption
+	// 校验模板变量，没有填写的使用默认值
+	c.UserOption.Variables, c.err = c.TmplOption.ResolveVariables(c.UserOption.Variables)
+	if c.err != nil {
+		c.err = fmt.Errorf("模板变量错误: %w", c.err)
+		return
+	}
+	c.StoreData.UserOption = c.UserOption
 
 	for _, value := range c.TmplOption.Descriptor {
 		if value.Once {
-			c.FunctionOnce[value.SrcName] = sync.Once{}
+			c.FunctionOnce[value.SrcName] = &sync.Once{}
 		}
 	}
 }
@@ -89,9 +96,10 @@ func (c *Container) initRender() {
 		return
 	}
 	for _, desc := range c.TmplOption.Descriptor {
+		// 不属于任何模块的描述总是生成
 		_, allFlag := c.EnableModules["*"]
 		_, moduleFlag := c.EnableModules[desc.Module]
-		if !allFlag && !moduleFlag {
+		if desc.Module != "" && !allFlag && !moduleFlag {
 			continue
 		}
 
@@ -100,14 +108,15 @@ func (c *Container) initRender() {
 		// model table name, model table schema
 		for _, m := range models {
 			// some render exec once
+			// 只生成一次的描述使用第一个模型渲染，when也只按第一个模型计算
 			syncOnce, flag := c.FunctionOnce[desc.SrcName]
 			if flag {
 				syncOnce.Do(func() {
 					c.err = c.renderModel(m)
-					if c.err != nil {
-						return
-					}
 				})
+				if c.err != nil {
+					return
+				}
 				continue
 			}
 			c.err = c.renderModel(m)
@@ -126,11 +135,23 @@ func (c *Container) renderModel(m RenderInfo) error {
 	if c.UserOption.Mode == "json" {
 		return nil
 	}
+	enabled, err := render.Enabled()
+	if err != nil {
+		return err
+	}
+	// 不满足when条件时不生成，之前生成过的文件由prune处理
+	if !enabled {
+		return nil
+	}
 
-	err := render.Exec(m.Descriptor.SrcName)
+	change, err := render.Exec(m.Descriptor.SrcName)
 	if err != nil {
 		return err
 	}
+	c.Changes = append(c.Changes, change)
+	if c.UserOption.DryRun {
+		return nil
+	}
 	if render.Descriptor.IsExistScript() {
 		err := render.Descriptor.ExecScript(c.CurPath)
 		if err != nil {
@@ -143,3 +164,8 @@ func (c *Container) renderModel(m RenderInfo) error {
 func (c *Container) GetRenderData() StoreData {
 	return c.StoreData
 }
+
+// GetChanges 获取每个目标文件的生成结果，DryRun模式下可用于预览
+func (c *Container) GetChanges() []FileChange {
+	return c.Changes
+}
@@ -0,0 +1,338 @@
+package parser
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles 在root下写入测试文件
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestContainerDryRun(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "project")
+	writeFiles(t, root, map[string]string{
+		"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+`,
+		"tmpl/ego-gin/ego/model.go.tmpl": "// @EgoctlOverwrite yes\npackage model\n\n// {$ modelName $}\n",
+		"project/go.mod":                 "module example.com/project\n\ngo 1.16\n",
+		"project/model/user.go":          "// @EgoctlOverwrite yes\npackage model\n\n// user\n",
+		"project/model/order.go":         "// @EgoctlOverwrite yes\npackage model\n\n// old order\n",
+		"project/model/product.go":       "// @EgoctlOverwrite no\npackage model\n\n// my product\n",
+	})
+	before, err := readTree(projectPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	container := NewParser(UserOption{
+		Language:           "Go",
+		ScaffoldDSLContent: "package egoctl\n\ntype User struct {\n\tId int\n}\n\ntype Order struct {\n\tId int\n}\n\ntype Item struct {\n\tId int\n}\n\ntype Product struct {\n\tId int\n}\n",
+		ProType:            "ego-gin",
+		ProjectPath:        projectPath,
+		GitLocalPath:       filepath.Join(root, "tmpl"),
+		Path:               map[string]string{"backend": "."},
+		DryRun:             true,
+	})
+	container.CurPath = projectPath
+	if err = container.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"model/user.go":    FileStatusUnchanged,
+		"model/order.go":   FileStatusChanged,
+		"model/item.go":    FileStatusNew,
+		"model/product.go": FileStatusProtected,
+	}
+	changes := container.GetChanges()
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes: %+v", len(changes), changes)
+	}
+	for _, change := range changes {
+		path := relativePath(projectPath, change.Path)
+		if change.Status != want[path] {
+			t.Errorf("%s: got status %s, want %s", path, change.Status, want[path])
+		}
+		if (change.Status == FileStatusUnchanged) != (change.Diff == "") {
+			t.Errorf("%s: got diff %q", path, change.Diff)
+		}
+	}
+
+	after, err := readTree(projectPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("dry run wrote files: got %d files, want %d", len(after), len(before))
+	}
+	for name, content := range before {
+		if !bytes.Equal(after[name], content) {
+			t.Errorf("dry run changed %s", name)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(projectPath, EgoctlDir)); !os.IsNotExist(err) {
+		t.Errorf("dry run created %s", EgoctlDir)
+	}
+}
+
+func TestContainerDroppedRegion(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "project")
+	org := "package model\n\n// egoctl:begin custom methods\nfunc (User) Hello() {}\n// egoctl:end\n"
+	writeFiles(t, root, map[string]string{
+		"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+`,
+		"tmpl/ego-gin/ego/model.go.tmpl": "package model\n\n// {$ modelName $}\n",
+		"project/go.mod":                 "module example.com/project\n\ngo 1.16\n",
+		"project/model/user.go":          org,
+	})
+	container := NewParser(UserOption{
+		Language:           "Go",
+		ScaffoldDSLContent: "package egoctl\n\ntype User struct {\n\tId int\n}\n",
+		ProType:            "ego-gin",
+		ProjectPath:        projectPath,
+		GitLocalPath:       filepath.Join(root, "tmpl"),
+		Path:               map[string]string{"backend": "."},
+	})
+	container.CurPath = projectPath
+	if err := container.Run(); err != nil {
+		t.Fatal(err)
+	}
+	changes := container.GetChanges()
+	if len(changes) != 1 || changes[0].Status != FileStatusConflict || len(changes[0].DroppedRegions) != 1 || changes[0].DroppedRegions[0] != "methods" {
+		t.Fatalf("got %+v", changes)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(projectPath, "model/user.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != org {
+		t.Fatalf("file with a dropped region was overwritten:\n%s", content)
+	}
+}
+
+func TestContainerProtectedBaseline(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "project")
+	writeFiles(t, root, map[string]string{
+		"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+
+[[descriptor]]
+srcName = "dao.go.tmpl"
+dstPath = "{$ pathBackend $}/dao/{$ modelName $}.go"
+`,
+		"tmpl/ego-gin/ego/model.go.tmpl": "// @EgoctlOverwrite yes\npackage model\n\n// {$ modelName $}\n",
+		"tmpl/ego-gin/ego/dao.go.tmpl":   "package dao\n\n// {$ modelName $}\n",
+		"project/go.mod":                 "module example.com/project\n\ngo 1.16\n",
+	})
+	run := func() []FileChange {
+		t.Helper()
+		container := NewParser(UserOption{
+			Language:           "Go",
+			ScaffoldDSLContent: "package egoctl\n\ntype User struct {\n\tId int\n}\n",
+			ProType:            "ego-gin",
+			ProjectPath:        projectPath,
+			GitLocalPath:       filepath.Join(root, "tmpl"),
+			Path:               map[string]string{"backend": "."},
+		})
+		container.CurPath = projectPath
+		if err := container.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return container.GetChanges()
+	}
+	// 第一次生成，记录基线
+	for _, change := range run() {
+		if change.Status != FileStatusNew {
+			t.Fatalf("got %+v", change)
+		}
+	}
+
+	// 用户声明不覆盖，或者修改了没有声明、没有手写代码区域的文件，模板也有变化
+	files := map[string]string{
+		"project/model/user.go": "// @EgoctlOverwrite no\npackage model\n\n// my user\n",
+		"project/dao/user.go":   "package dao\n\n// my user\n",
+	}
+	writeFiles(t, root, files)
+	writeFiles(t, root, map[string]string{
+		"tmpl/ego-gin/ego/model.go.tmpl": "// @EgoctlOverwrite yes\npackage model\n\n// {$ modelName $} v2\n",
+		"tmpl/ego-gin/ego/dao.go.tmpl":   "package dao\n\n// {$ modelName $} v2\n",
+	})
+	for i := 0; i < 2; i++ {
+		changes := run()
+		if len(changes) != 2 {
+			t.Fatalf("got %+v", changes)
+		}
+		for _, change := range changes {
+			if change.Status != FileStatusProtected || change.Conflicts != 0 {
+				t.Fatalf("run %d: got %+v", i, change)
+			}
+		}
+		for name, want := range files {
+			content, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != want {
+				t.Fatalf("run %d: protected %s was overwritten:\n%s", i, name, content)
+			}
+		}
+	}
+}
+
+func TestContainerOnceWhen(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "project")
+	writeFiles(t, root, map[string]string{
+		"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "router.go.tmpl"
+dstPath = "{$ pathBackend $}/router/router.go"
+once = true
+when = 'modelName == "user"'
+
+[[descriptor]]
+srcName = "init.go.tmpl"
+dstPath = "{$ pathBackend $}/model/init.go"
+once = true
+when = 'modelName == "order"'
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+`,
+		"tmpl/ego-gin/ego/router.go.tmpl": "package router\n",
+		"tmpl/ego-gin/ego/init.go.tmpl":   "package model\n",
+		"tmpl/ego-gin/ego/model.go.tmpl":  "package model\n\n// {$ modelName $}\n",
+		"project/go.mod":                  "module example.com/project\n\ngo 1.16\n",
+	})
+	option := UserOption{
+		Language:           "Go",
+		ScaffoldDSLContent: "package egoctl\n\ntype User struct {\n\tId int\n}\n\ntype Order struct {\n\tId int\n}\n",
+		ProType:            "ego-gin",
+		ProjectPath:        projectPath,
+		GitLocalPath:       filepath.Join(root, "tmpl"),
+		Path:               map[string]string{"backend": "."},
+		DryRun:             true,
+	}
+	container := NewParser(option)
+	container.CurPath = projectPath
+	if err := container.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// once的when只按第一个模型Order计算
+	got := make([]string, 0)
+	for _, change := range container.GetChanges() {
+		got = append(got, relativePath(projectPath, change.Path))
+	}
+	want := "model/init.go model/order.go model/user.go"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+
+	// once描述的错误不会被后面的描述覆盖
+	writeFiles(t, root, map[string]string{
+		"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "router.go.tmpl"
+dstPath = "{$ pathBackend $}/router/router.go"
+once = true
+when = "modelName =="
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+`,
+	})
+	container = NewParser(option)
+	container.CurPath = projectPath
+	if err := container.Run(); err == nil || !strings.Contains(err.Error(), "router.go.tmpl") {
+		t.Fatalf("got error %v, want when error of router.go.tmpl", err)
+	}
+}
+
+func TestContainerEnableModule(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "project")
+	writeFiles(t, root, map[string]string{
+		"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[module]]
+name = "admin"
+
+[[descriptor]]
+srcName = "main.go.tmpl"
+dstPath = "{$ pathBackend $}/main.go"
+once = true
+
+[[descriptor]]
+module = "admin"
+srcName = "admin.go.tmpl"
+dstPath = "{$ pathBackend $}/admin/{$ modelName $}.go"
+`,
+		"tmpl/ego-gin/ego/main.go.tmpl":  "package main\n",
+		"tmpl/ego-gin/ego/admin.go.tmpl": "package admin\n",
+		"project/go.mod":                 "module example.com/project\n\ngo 1.16\n",
+	})
+	cases := []struct {
+		enabled []string
+		want    string
+	}{
+		{enabled: nil, want: "main.go admin/user.go"}, // 没有配置过模块时生成所有模块
+		{enabled: []string{}, want: "main.go"},        // 明确不开启任何模块
+		{enabled: []string{"admin"}, want: "main.go admin/user.go"},
+	}
+	for _, c := range cases {
+		container := NewParser(UserOption{
+			Language:           "Go",
+			ScaffoldDSLContent: "package egoctl\n\ntype User struct {\n\tId int\n}\n",
+			ProType:            "ego-gin",
+			ProjectPath:        projectPath,
+			GitLocalPath:       filepath.Join(root, "tmpl"),
+			Path:               map[string]string{"backend": "."},
+			EnableModule:       c.enabled,
+			DryRun:             true,
+		})
+		container.CurPath = projectPath
+		if err := container.Run(); err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0)
+		for _, change := range container.GetChanges() {
+			got = append(got, relativePath(projectPath, change.Path))
+		}
+		if strings.Join(got, " ") != c.want {
+			t.Fatalf("enabled %#v: got %v, want %s", c.enabled, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Diagnostic DSL中的一处错误，行列从1开始
+type Diagnostic struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// Diagnostics DSL中的所有错误，编辑器可以据此标注出错的行
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	arr := make([]string, 0, len(d))
+	for _, value := range d {
+		arr = append(arr, fmt.Sprintf("%d:%d: %s", value.Line, value.Column, value.Message))
+	}
+	return strings.Join(arr, "\n")
+}
+
+func (d Diagnostics) sort() Diagnostics {
+	sort.SliceStable(d, func(i, j int) bool {
+		if d[i].Line != d[j].Line {
+			return d[i].Line < d[j].Line
+		}
+		return d[i].Column < d[j].Column
+	})
+	return d
+}
+
+// fromScannerErrors 将go/parser的语法错误转换为Diagnostics
+func fromScannerErrors(err error) Diagnostics {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return Diagnostics{{Line: 1, Column: 1, Message: err.Error()}}
+	}
+	output := make(Diagnostics, 0, len(list))
+	for _, value := range list {
+		output = append(output, Diagnostic{
+			Line:    value.Pos.Line,
+			Column:  value.Pos.Column,
+			Message: value.Msg,
+		})
+	}
+	return output
+}
+
+// addDiagnostic 记录pos处的错误，相同位置相同信息只记录一次
+func (a *astParser) addDiagnostic(pos token.Pos, message string) {
+	position := a.fSet.Position(pos)
+	for _, value := range a.diagnostics {
+		if value.Line == position.Line && value.Column == position.Column && value.Message == message {
+			return
+		}
+	}
+	a.diagnostics = append(a.diagnostics, Diagnostic{
+		Line:    position.Line,
+		Column:  position.Column,
+		Message: message,
+	})
+}
+
+// CheckDSL 检查DSL内容，返回所有语法和类型错误
+func CheckDSL(content string) Diagnostics {
+	_, err := AstParserBuild(UserOption{ScaffoldDSLContent: content}, TmplOption{})
+	if err == nil {
+		return Diagnostics{}
+	}
+	if diagnostics, ok := err.(Diagnostics); ok {
+		return diagnostics
+	}
+	return Diagnostics{{Line: 1, Column: 1, Message: err.Error()}}
+}
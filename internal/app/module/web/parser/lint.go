@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser/pongo2"
+	"github.com/gotomicro/egoctl/internal/utils"
+	"github.com/pelletier/go-toml"
+)
+
+// TmplConfigFile 模板类型目录下的配置文件
+const TmplConfigFile = "egoctl.toml"
+
+// lintModelNames 检查dstPath时使用的示例模型
+var lintModelNames = []string{"User", "Order"}
+
+// LintProblem 模板检查发现的问题
+type LintProblem struct {
+	File    string `json:"file"` // 相对模板类型目录的路径
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+func (p LintProblem) String() string {
+	switch {
+	case p.Line > 0 && p.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+	case p.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.File, p.Message)
+}
+
+type linter struct {
+	dir         string       // 模板类型目录
+	descriptors []*toml.Tree // egoctl.toml中的descriptor，用于定位行号
+	problems    []LintProblem
+}
+
+// LintTemplate 检查模板类型目录：egoctl.toml能否解析，srcName是否存在，模板能否被pongo2解析，
+// dstPath、script、when能否渲染，以及是否有多个descriptor写入同一个文件
+func LintTemplate(dir string) []LintProblem {
+	l := &linter{dir: dir, problems: make([]LintProblem, 0)}
+	tree, err := toml.LoadFile(filepath.Join(dir, TmplConfigFile))
+	if err != nil {
+		l.add(TmplConfigFile, toml.Position{}, err.Error())
+		return l.problems
+	}
+	option := TmplOption{}
+	if err = tree.Unmarshal(&option); err != nil {
+		l.add(TmplConfigFile, toml.Position{}, err.Error())
+		return l.problems
+	}
+	l.descriptors, _ = tree.Get("descriptor").([]*toml.Tree)
+
+	renderDir := filepath.Join(dir, option.RenderPath)
+	if !utils.IsDir(renderDir) {
+		l.add(TmplConfigFile, tree.GetPosition("renderPath"), fmt.Sprintf("renderPath %s不是目录", option.RenderPath))
+	} else {
+		l.lintTemplates(renderDir)
+	}
+	l.lintModules(tree, option)
+	variables := l.lintVariables(tree, option)
+	l.lintDescriptors(option, renderDir, variables)
+
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		if l.problems[i].File != l.problems[j].File {
+			return l.problems[i].File < l.problems[j].File
+		}
+		return l.problems[i].Line < l.problems[j].Line
+	})
+	return l.problems
+}
+
+func (l *linter) add(file string, pos toml.Position, message string) {
+	l.problems = append(l.problems, LintProblem{File: file, Line: pos.Line, Column: pos.Col, Message: message})
+}
+
+// descriptorPosition descriptor中某个key的位置，key不存在时为descriptor的位置
+func (l *linter) descriptorPosition(index int, key string) toml.Position {
+	if index >= len(l.descriptors) {
+		return toml.Position{}
+	}
+	if pos := l.descriptors[index].GetPosition(key); !pos.Invalid() {
+		return pos
+	}
+	return l.descriptors[index].Position()
+}
+
+// lintTemplates 用pongo2解析renderPath下的所有模板，未注册的filter、tag也会在解析时报错
+func (l *linter) lintTemplates(renderDir string) {
+	_ = filepath.Walk(renderDir, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			l.add(l.rel(filename), toml.Position{}, err.Error())
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") && filename != renderDir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			// renderPath为模板类型目录时，跳过模板测试用例
+			if filename == filepath.Join(l.dir, TemplateTestDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, err = pongo2.DefaultSet.FromFile(filename); err != nil {
+			l.addTemplateError(l.rel(filename), toml.Position{}, err)
+		}
+		return nil
+	})
+}
+
+// addTemplateError 添加pongo2的错误，模板文件的错误使用pongo2给出的文件和行号，字符串模板使用egoctl.toml中的位置
+func (l *linter) addTemplateError(file string, pos toml.Position, err error) {
+	var pErr *pongo2.Error
+	if !errors.As(err, &pErr) {
+		l.add(file, pos, err.Error())
+		return
+	}
+	message := pErr.Error()
+	if pErr.OrigError != nil {
+		message = pErr.OrigError.Error()
+	}
+	if pErr.Filename != "" && pErr.Filename != "<string>" {
+		file = l.rel(pErr.Filename)
+		pos = toml.Position{Line: pErr.Line, Col: pErr.Column}
+	}
+	l.add(file, pos, message)
+}
+
+func (l *linter) rel(filename string) string {
+	if rel, err := filepath.Rel(l.dir, filename); err == nil {
+		return rel
+	}
+	return filename
+}
+
+// lintModules 检查模块依赖的模块是否存在
+func (l *linter) lintModules(tree *toml.Tree, option TmplOption) {
+	modules := make(map[string]bool)
+	for _, module := range option.ModuleList() {
+		modules[module.Name] = true
+	}
+	trees, _ := tree.Get("module").([]*toml.Tree)
+	for i, module := range option.Modules {
+		pos := toml.Position{}
+		if i < len(trees) {
+			pos = trees[i].Position()
+		}
+		if module.Name == "" {
+			l.add(TmplConfigFile, pos, "module缺少name")
+		}
+		for _, depend := range module.Depends {
+			if !modules[depend] {
+				l.add(TmplConfigFile, pos, fmt.Sprintf("模块%s依赖的模块%s不存在", module.Name, depend))
+			}
+		}
+	}
+}
+
+// lintVariables 检查变量的类型和默认值，返回检查dstPath时使用的默认值
+func (l *linter) lintVariables(tree *toml.Tree, option TmplOption) map[string]interface{} {
+	trees, _ := tree.Get("variables").([]*toml.Tree)
+	values := make(map[string]interface{})
+	for i, variable := range option.Variables {
+		pos := toml.Position{}
+		if i < len(trees) {
+			pos = trees[i].Position()
+		}
+		single := TmplOption{Variables: []TmplVariable{variable}}
+		resolved, err := single.ResolveVariables(nil)
+		if err != nil {
+			l.add(TmplConfigFile, pos, err.Error())
+			continue
+		}
+		values[variable.Name] = resolved[variable.Name]
+	}
+	return values
+}
+
+// lintDescriptors 检查每个descriptor，并用示例模型渲染dstPath，找出写入同一个文件的descriptor
+func (l *linter) lintDescriptors(option TmplOption, renderDir string, variables map[string]interface{}) {
+	userOption := UserOption{
+		ProType:     filepath.Base(l.dir),
+		ProjectPath: ".",
+		Variables:   variables,
+		Path:        map[string]string{"backend": "."},
+	}
+	// 目标文件 -> 第一个写入该文件的descriptor
+	written := make(map[string]int)
+	for i, desc := range option.Descriptor {
+		name := fmt.Sprintf("descriptor %s", desc.SrcName)
+		if desc.SrcName == "" {
+			l.add(TmplConfigFile, l.descriptorPosition(i, "srcName"), "descriptor缺少srcName")
+			name = fmt.Sprintf("第%d个descriptor", i+1)
+		} else if !utils.IsExist(filepath.Join(renderDir, desc.SrcName)) {
+			l.add(TmplConfigFile, l.descriptorPosition(i, "srcName"), fmt.Sprintf("%s: 模板文件%s不存在", name, filepath.Join(option.RenderPath, desc.SrcName)))
+		}
+		if desc.When != "" {
+			if _, err := pongo2.FromString("{% if " + desc.When + " %}{% endif %}"); err != nil {
+				l.addTemplateError(TmplConfigFile, l.descriptorPosition(i, "when"), fmt.Errorf("%s: when表达式错误: %w", name, err))
+			}
+		}
+		if desc.Script != "" {
+			if _, err := pongo2.FromString(desc.Script); err != nil {
+				l.addTemplateError(TmplConfigFile, l.descriptorPosition(i, "script"), fmt.Errorf("%s: script错误: %w", name, err))
+			}
+		}
+		if desc.DstPath == "" {
+			l.add(TmplConfigFile, l.descriptorPosition(i, "dstPath"), fmt.Sprintf("%s: 缺少dstPath", name))
+			continue
+		}
+		tpl, err := pongo2.FromString(desc.DstPath)
+		if err != nil {
+			l.addTemplateError(TmplConfigFile, l.descriptorPosition(i, "dstPath"), fmt.Errorf("%s: dstPath错误: %w", name, err))
+			continue
+		}
+
+		models := lintModelNames
+		if desc.Once {
+			models = models[:1]
+		}
+		dstPaths := make([]string, 0, len(models))
+		conflicted := false
+		for _, model := range models {
+			dstPath, err := executeSafely(tpl, descriptorContext(userOption, model, lintModelNames, userOption.Path))
+			if err != nil {
+				l.addTemplateError(TmplConfigFile, l.descriptorPosition(i, "dstPath"), fmt.Errorf("%s: dstPath渲染失败: %w", name, err))
+				break
+			}
+			dstPath = filepath.Clean(dstPath)
+			dstPaths = append(dstPaths, dstPath)
+			// 两个descriptor都有when时可能互斥，无法静态判断
+			if j, ok := written[dstPath]; ok && j != i && (desc.When == "" || option.Descriptor[j].When == "") {
+				if !conflicted {
+					l.add(TmplConfigFile, l.descriptorPosition(i, "dstPath"), fmt.Sprintf("%s与descriptor %s写入同一个文件%s", name, option.Descriptor[j].SrcName, dstPath))
+				}
+				conflicted = true
+				continue
+			}
+			written[dstPath] = i
+		}
+		if len(dstPaths) == len(lintModelNames) && dstPaths[0] == dstPaths[1] {
+			l.add(TmplConfigFile, l.descriptorPosition(i, "dstPath"), fmt.Sprintf("%s: dstPath与模型无关，每个模型都会写入%s，只需要生成一次时请设置once = true", name, dstPaths[0]))
+		}
+	}
+}
+
+// executeSafely 渲染模板，pongo2中的panic作为错误返回
+func executeSafely(tpl *pongo2.Template, ctx pongo2.Context) (output string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return tpl.Execute(ctx)
+}
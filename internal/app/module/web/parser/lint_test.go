@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintTemplate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ego-gin")
+	err := os.MkdirAll(filepath.Join(dir, "ego"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"egoctl.toml": `renderPath = "ego"
+
+[[module]]
+name = "upload"
+depends = ["oss"]
+
+[[variables]]
+name = "port"
+type = "int"
+default = "abc"
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName|snakeString $}.go"
+
+[[descriptor]]
+srcName = "api.go.tmpl"
+dstPath = "{$ pathBackend $}/api.go"
+
+[[descriptor]]
+srcName = "missing.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName|snakeString $}.go"
+`,
+		"ego/model.go.tmpl": "package model\n",
+		"ego/api.go.tmpl":   "package api\n\n{$ modelName|noSuchFilter $}\n",
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems := LintTemplate(dir)
+	want := []string{
+		"egoctl.toml:3:1: 模块upload依赖的模块oss不存在",
+		"egoctl.toml:7:1: 变量port的默认值",
+		"egoctl.toml:18:1: descriptor api.go.tmpl: dstPath与模型无关",
+		"egoctl.toml:21:1: descriptor missing.go.tmpl: 模板文件ego/missing.go.tmpl不存在",
+		"egoctl.toml:22:1: descriptor missing.go.tmpl与descriptor model.go.tmpl写入同一个文件",
+		"ego/api.go.tmpl:3:14: Filter 'noSuchFilter' does not exist.",
+	}
+	got := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got problems:\n%s", strings.Join(got, "\n"))
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if strings.HasPrefix(g, w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("want problem %q, got:\n%s", w, strings.Join(got, "\n"))
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gotomicro/egoctl/internal/git"
+	"github.com/gotomicro/egoctl/internal/utils"
+)
+
+const ManifestFile = "manifest.json" // 生成清单，记录egoctl生成的所有文件
+
+// Manifest 生成清单
+type Manifest struct {
+	GenerateTime    int64          `json:"generateTime"`    // 最近一次生成时间
+	ProType         string         `json:"proType"`         // 模板类型
+	TemplateVersion string         `json:"templateVersion"` // 最近一次生成时模板的git版本
+	Files           []ManifestItem `json:"files"`
+}
+
+// ManifestItem 单个生成文件的记录
+type ManifestItem struct {
+	Path            string `json:"path"`            // 相对项目目录的路径
+	SrcName         string `json:"srcName"`         // 模板文件名称
+	ModelName       string `json:"modelName"`       // 模型名称
+	TemplateVersion string `json:"templateVersion"` // 生成该文件时模板的git版本
+	Hash            string `json:"hash"`            // 写入文件内容的md5
+	GenerateTime    int64  `json:"generateTime"`    // 生成该文件的时间
+}
+
+// manifestPath 生成清单的路径，项目绑定多个模板时每个模板有各自的生成清单，主模板使用manifest.json
+func manifestPath(projectPath string, binding string) string {
+	if binding == "" {
+		return filepath.Join(projectPath, EgoctlDir, ManifestFile)
+	}
+	return filepath.Join(projectPath, EgoctlDir, "manifest-"+binding+".json")
+}
+
+// ReadManifest 读取项目中某个模板的生成清单，不存在时返回空清单
+func ReadManifest(projectPath string, binding string) (Manifest, error) {
+	manifest := Manifest{Files: make([]ManifestItem, 0)}
+	filename := manifestPath(projectPath, binding)
+	if !utils.IsExist(filename) {
+		return manifest, nil
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return manifest, fmt.Errorf("读取生成清单失败, err: %w", err)
+	}
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("解析生成清单失败, err: %w", err)
+	}
+	return manifest, nil
+}
+
+// WriteManifest 写入项目中某个模板的生成清单
+func WriteManifest(projectPath string, binding string, manifest Manifest) error {
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码生成清单失败, err: %w", err)
+	}
+	err = createPath(filepath.Join(projectPath, EgoctlDir))
+	if err != nil {
+		return fmt.Errorf("创建生成清单目录失败, err: %w", err)
+	}
+	return ioutil.WriteFile(manifestPath(projectPath, binding), content, 0644)
+}
+
+// Find 根据相对路径查找记录
+func (m Manifest) Find(path string) (ManifestItem, bool) {
+	for _, item := range m.Files {
+		if item.Path == path {
+			return item, true
+		}
+	}
+	return ManifestItem{}, false
+}
+
+// ContentHash 计算文件内容的md5
+func ContentHash(content []byte) string {
+	return fmt.Sprintf("%x", md5.Sum(content))
+}
+
+// templateVersion 获取模板的git版本，不是git仓库时返回空
+func templateVersion(path string) string {
+	repo, err := git.OpenRepository(path)
+	if err != nil {
+		return ""
+	}
+	version, err := repo.GetVersion()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(version)
+}
+
+// relativePath 获取相对项目目录的路径
+func relativePath(projectPath string, filename string) string {
+	rel, err := filepath.Rel(projectPath, filename)
+	if err != nil {
+		return filename
+	}
+	return filepath.ToSlash(rel)
+}
+
+// writeManifest 根据本次生成结果更新生成清单，没有写入的文件保留上一次的记录
+func (c *Container) writeManifest() {
+	if c.err != nil || c.UserOption.DryRun || c.UserOption.Mode == "json" {
+		return
+	}
+	previous, err := ReadManifest(c.UserOption.ProjectPath, c.UserOption.Binding)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	version := templateVersion(c.UserOption.GitLocalPath)
+	manifest := Manifest{
+		GenerateTime:    c.GenerateTimeUnix,
+		ProType:         c.UserOption.ProType,
+		TemplateVersion: version,
+		Files:           make([]ManifestItem, 0),
+	}
+	for _, change := range c.Changes {
+		path := relativePath(c.UserOption.ProjectPath, change.Path)
+		prevItem, ok := previous.Find(path)
+		// 手写代码区域被新模板去掉的文件没有写入，保留上一次的记录
+		if len(change.DroppedRegions) > 0 {
+			if ok {
+				manifest.Files = append(manifest.Files, prevItem)
+			}
+			continue
+		}
+		switch change.Status {
+		case FileStatusRemoved:
+			continue
+		case FileStatusProtected, FileStatusObsolete, FileStatusOrphaned:
+			if ok {
+				manifest.Files = append(manifest.Files, prevItem)
+			}
+			continue
+		}
+		item := ManifestItem{
+			Path:            path,
+			SrcName:         change.SrcName,
+			ModelName:       change.ModelName,
+			TemplateVersion: version,
+			Hash:            change.Hash,
+			GenerateTime:    c.GenerateTimeUnix,
+		}
+		// 内容没有变化的文件，保留原来的生成时间
+		if change.Status == FileStatusUnchanged && ok {
+			item.GenerateTime = prevItem.GenerateTime
+		}
+		manifest.Files = append(manifest.Files, item)
+	}
+	c.err = WriteManifest(c.UserOption.ProjectPath, c.UserOption.Binding, manifest)
+}
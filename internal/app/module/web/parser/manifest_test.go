@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	projectPath := t.TempDir()
+	manifest := Manifest{
+		GenerateTime:    1600000000,
+		ProType:         "ego-gin",
+		TemplateVersion: "abc",
+		Files: []ManifestItem{
+			{Path: "model/user.go", SrcName: "model.go.tmpl", ModelName: "User", Hash: ContentHash([]byte("user"))},
+			{Path: "api/user.go", SrcName: "api.go.tmpl", ModelName: "User", Hash: ContentHash([]byte("api"))},
+		},
+	}
+	if err := WriteManifest(projectPath, "", manifest); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadManifest(projectPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 写入时按路径排序
+	if got.ProType != "ego-gin" || got.TemplateVersion != "abc" || got.GenerateTime != 1600000000 ||
+		len(got.Files) != 2 || got.Files[0].Path != "api/user.go" || got.Files[1].Path != "model/user.go" {
+		t.Fatalf("got %+v", got)
+	}
+	item, ok := got.Find("model/user.go")
+	if !ok || item.Hash != ContentHash([]byte("user")) {
+		t.Fatalf("find: got %+v, %v", item, ok)
+	}
+	if _, ok = got.Find("model/order.go"); ok {
+		t.Fatal("want model/order.go not found")
+	}
+}
+
+func TestReadManifestMissing(t *testing.T) {
+	manifest, err := ReadManifest(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Files == nil || len(manifest.Files) != 0 {
+		t.Fatalf("got %+v", manifest)
+	}
+}
+
+func TestReadManifestCorrupt(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectPath, EgoctlDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := ioutil.WriteFile(filepath.Join(projectPath, EgoctlDir, ManifestFile), []byte("{\"files\": ["), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadManifest(projectPath, ""); err == nil {
+		t.Fatal("want error for corrupt manifest")
+	}
+}
+
+func TestManifestPerBinding(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "project")
+	files := map[string]string{
+		"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+`,
+		"tmpl/ego-gin/ego/model.go.tmpl": "package model\n\n// {$ modelName $}\n",
+		"tmpl/antd/egoctl.toml": `renderPath = "src"
+
+[[descriptor]]
+srcName = "page.tsx.tmpl"
+dstPath = "{$ pathFrontend $}/pages/{$ modelName $}.tsx"
+`,
+		"tmpl/antd/src/page.tsx.tmpl": "export default '{$ modelName $}';\n",
+		"project/go.mod":              "module example.com/project\n\ngo 1.16\n",
+	}
+	for name, content := range files {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := map[string]string{"backend": ".", "frontend": "web"}
+	options := []UserOption{
+		{Language: "Go", ProType: "ego-gin", Path: paths},
+		{Language: "React", ProType: "antd", Path: paths, Binding: "frontend"},
+	}
+	for round := 0; round < 2; round++ {
+		for _, option := range options {
+			option.ScaffoldDSLContent = "package egoctl\n\ntype User struct {\n\tId int\n}\n"
+			option.ProjectPath = projectPath
+			option.GitLocalPath = filepath.Join(root, "tmpl")
+			option.Prune = true
+			container := NewParser(option)
+			container.CurPath = projectPath
+			if err := container.Run(); err != nil {
+				t.Fatal(err)
+			}
+			for _, change := range container.GetChanges() {
+				if change.Status == FileStatusObsolete || change.Status == FileStatusRemoved {
+					t.Fatalf("round %d %s: got %+v", round, option.ProType, change)
+				}
+			}
+		}
+	}
+
+	backend, err := ReadManifest(projectPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frontend, err := ReadManifest(projectPath, "frontend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.Files) != 1 || backend.Files[0].Path != "model/user.go" {
+		t.Fatalf("got backend manifest %+v", backend.Files)
+	}
+	if len(frontend.Files) != 1 || frontend.Files[0].Path != "web/pages/user.tsx" {
+		t.Fatalf("got frontend manifest %+v", frontend.Files)
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"strings"
 )
 
+// SpecAnnotation 注释中的注解，例如 // @table(name=users, softDelete=true)
 type SpecAnnotation struct {
-	Name       string
-	Properties map[string]string
-	Value      string
+	Name       string            `json:"name"`       // 注解名称，例如 table
+	Properties map[string]string `json:"properties"` // 注解属性，例如 name=users
+	Value      string            `json:"value"`      // 注解的值，例如 @search(like) 中的 like
 }
 
 type SpecMember struct {
@@ -56,6 +57,8 @@ type SpecType struct {
 	Name        string
 	Annotations []SpecAnnotation
 	Members     []SpecMember
+	// 结构体头顶注释说明
+	Docs []string
 }
 
 func (content SpecType) ToModelInfos() (output []ModelSchema) {
@@ -76,10 +79,12 @@ func (content SpecType) ToModelInfos() (output []ModelSchema) {
 		}
 
 		m := ModelSchema{
-			FieldName:    member.Name,
-			FieldType:    member.Type,
-			FieldTags:    tags,
-			FieldComment: comment,
+			FieldName:           member.Name,
+			FieldType:           member.Type,
+			FieldTags:           tags,
+			FieldComment:        comment,
+			FieldAnnotations:    annotationMap(member.Annotations),
+			FieldAnnotationList: member.Annotations,
 		}
 		output = append(output, m)
 	}
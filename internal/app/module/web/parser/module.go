@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml"
+)
+
+// TmplModule 模板在egoctl.toml中声明的模块
+//
+//	[[module]]
+//	name = "upload"
+//	description = "文件上传"
+//	depends = ["oss"]
+type TmplModule struct {
+	Name        string   `toml:"name" json:"name"`
+	Description string   `toml:"description" json:"description"`
+	Depends     []string `toml:"depends" json:"depends"` // 依赖的模块，开启该模块时会一起生成
+}
+
+// LoadTmplOption 读取模板类型目录下的egoctl.toml
+func LoadTmplOption(gitLocalPath string, proType string) (option TmplOption, err error) {
+	tree, err := toml.LoadFile(filepath.Join(gitLocalPath, proType, TmplConfigFile))
+	if err != nil {
+		return option, fmt.Errorf("egoctl tmpl exec error, err: %w", err)
+	}
+	err = tree.Unmarshal(&option)
+	if err != nil {
+		return option, fmt.Errorf("egoctl tmpl parse error, err: %w", err)
+	}
+	return option, nil
+}
+
+// ModuleList 模板的所有模块，descriptor中使用了但没有声明的模块也会返回
+func (o TmplOption) ModuleList() []TmplModule {
+	output := make([]TmplModule, 0, len(o.Modules))
+	exist := make(map[string]bool)
+	for _, module := range o.Modules {
+		exist[module.Name] = true
+		output = append(output, module)
+	}
+	for _, desc := range o.Descriptor {
+		if desc.Module == "" || exist[desc.Module] {
+			continue
+		}
+		exist[desc.Module] = true
+		output = append(output, TmplModule{Name: desc.Module})
+	}
+	return output
+}
+
+// ResolveModules 计算需要生成的模块，包括开启的模块依赖的模块。
+// 没有配置过模块（nil）时生成所有模块，配置为空列表时不生成任何模块，只生成不属于模块的描述
+func (o TmplOption) ResolveModules(enabled []string) (map[string]interface{}, error) {
+	output := make(map[string]interface{})
+	if enabled == nil {
+		output["*"] = struct{}{}
+		return output, nil
+	}
+	modules := make(map[string]TmplModule)
+	for _, module := range o.ModuleList() {
+		modules[module.Name] = module
+	}
+	queue := append([]string{}, enabled...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := output[name]; ok {
+			continue
+		}
+		module, ok := modules[name]
+		if !ok {
+			return nil, fmt.Errorf("模板中不存在模块%s", name)
+		}
+		output[name] = struct{}{}
+		queue = append(queue, module.Depends...)
+	}
+	return output, nil
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestResolveModules(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "ego-gin"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "ego-gin", "egoctl.toml"), []byte(`
+[[module]]
+name = "upload"
+description = "文件上传"
+depends = ["oss"]
+
+[[module]]
+name = "oss"
+depends = ["upload"]
+
+[[descriptor]]
+module = "admin"
+srcName = "admin.go.tmpl"
+dstPath = "admin.go"
+
+[[descriptor]]
+srcName = "main.go.tmpl"
+dstPath = "main.go"
+once = true
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	option, err := LoadTmplOption(dir, "ego-gin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, module := range option.ModuleList() {
+		names = append(names, module.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"upload", "oss", "admin"}) {
+		t.Fatalf("got modules %v", names)
+	}
+
+	cases := []struct {
+		enabled []string
+		want    []string
+	}{
+		{enabled: nil, want: []string{"*"}},
+		{enabled: []string{}, want: []string{}},
+		{enabled: []string{"upload"}, want: []string{"oss", "upload"}},
+		{enabled: []string{"admin"}, want: []string{"admin"}},
+	}
+	for _, c := range cases {
+		modules, err := option.ResolveModules(c.enabled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0, len(modules))
+		for name := range modules {
+			got = append(got, name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("enabled %v: got %v, want %v", c.enabled, got, c.want)
+		}
+	}
+	if _, err = option.ResolveModules([]string{"pay"}); err == nil {
+		t.Fatal("want error for unknown module")
+	}
+}
@@ -13,9 +13,13 @@ import (
 type astParser struct {
 	objectM     map[string]*SpecType // parser struct文件
 	modelArr    []SpecType           // 模型生成的描述文件
+	relations   RelationGraph        // 模型之间的关联关系
 	readContent string               // 读取原文件数据
 	userOption  UserOption
 	tmplOption  TmplOption
+	fSet        *token.FileSet
+	diagnostics Diagnostics                  // 解析过程中收集的所有错误
+	typeDocs    map[string]*ast.CommentGroup // 类型的注释
 }
 
 func AstParserBuild(userOption UserOption, tmplOption TmplOption) (*astParser, error) {
@@ -23,15 +27,31 @@ func AstParserBuild(userOption UserOption, tmplOption TmplOption) (*astParser, e
 		userOption: userOption,
 		tmplOption: tmplOption,
 		objectM:    make(map[string]*SpecType),
+		typeDocs:   make(map[string]*ast.CommentGroup),
 	}
 	err := a.initReadContent()
 	if err != nil {
 		return nil, err
 	}
-	a.parserStruct()
+	err = a.parserStruct()
+	if err != nil {
+		return nil, err
+	}
+	if len(a.diagnostics) > 0 {
+		return nil, a.diagnostics.sort()
+	}
 	return a, nil
 }
 
+// ParseDSL 解析DSL，返回按名称排序的模型
+func ParseDSL(content string) ([]SpecType, error) {
+	a, err := AstParserBuild(UserOption{ScaffoldDSLContent: content}, TmplOption{})
+	if err != nil {
+		return nil, err
+	}
+	return a.modelArr, nil
+}
+
 func (a *astParser) initReadContent() error {
 	if a.userOption.ScaffoldDSLContent == "" {
 		return fmt.Errorf("内容不能为空")
@@ -41,16 +61,17 @@ func (a *astParser) initReadContent() error {
 }
 
 func (a *astParser) parserStruct() error {
-	fSet := token.NewFileSet()
+	a.fSet = token.NewFileSet()
 
 	// strings.NewReader
-	f, err := parser.ParseFile(fSet, "", strings.NewReader(a.readContent), parser.ParseComments)
+	f, err := parser.ParseFile(a.fSet, "", strings.NewReader(a.readContent), parser.ParseComments|parser.AllErrors)
 	if err != nil {
-		panic(err)
+		return fromScannerErrors(err).sort()
 	}
 
-	commentMap := ast.NewCommentMap(fSet, f, f.Comments)
+	commentMap := ast.NewCommentMap(a.fSet, f, f.Comments)
 	f.Comments = commentMap.Filter(f).Comments()
+	a.collectTypeDocs(f)
 
 	scope := f.Scope
 	if scope == nil {
@@ -74,6 +95,7 @@ func (a *astParser) parserStruct() error {
 		resp = append(resp, *item)
 	}
 	a.modelArr = resp
+	a.relations = buildRelations(resp)
 	return nil
 }
 
@@ -86,14 +108,27 @@ func (t *astParser) GetRenderInfos(descriptor Descriptor) (output []RenderInfo)
 
 	// model table name, model table schema
 	for _, content := range t.modelArr {
+		relations := t.relations.Of(content.Name)
+		schemas := content.ToModelInfos()
+		for i := range schemas {
+			for j := range relations {
+				if relations[j].FieldName == schemas[i].FieldName {
+					schemas[i].FieldRelation = &relations[j]
+				}
+			}
+		}
 		output = append(output, RenderInfo{
-			Module:     descriptor.Module,
-			ModelNames: modelNames,
-			ModelName:  content.Name,
-			Content:    content.ToModelInfos(),
-			Option:     t.userOption,
-			Descriptor: descriptor,
-			TmplPath:   t.tmplOption.RenderPath,
+			Module:         descriptor.Module,
+			ModelNames:     modelNames,
+			ModelName:      content.Name,
+			Annotations:    annotationMap(content.Annotations),
+			AnnotationList: content.Annotations,
+			Relations:      relations,
+			Graph:          t.relations,
+			Content:        schemas,
+			Option:         t.userOption,
+			Descriptor:     descriptor,
+			TmplPath:       t.tmplOption.RenderPath,
 		})
 	}
 	return
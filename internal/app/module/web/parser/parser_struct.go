@@ -10,6 +10,10 @@ func (c *astParser) parseObject(structName string, obj *ast.Object) (*SpecType,
 	}
 	var st SpecType
 	st.Name = structName
+	if doc, ok := c.typeDocs[structName]; ok {
+		st.Docs = parseCommentOrDoc(doc)
+		st.Annotations = c.parseAnnotations(doc)
+	}
 	if obj.Decl == nil {
 		c.objectM[structName] = &st
 		return &st, nil
@@ -49,9 +53,16 @@ func (c *astParser) parseFields(fields []*ast.Field) ([]SpecMember, error) {
 		docs := parseCommentOrDoc(field.Doc)
 		comments := parseCommentOrDoc(field.Comment)
 		name := parseName(field.Names)
+		// 类型和tag错误记录到diagnostics中，继续解析其他字段
 		tp, stringExpr, err := c.parseType(field.Type)
 		if err != nil {
-			return nil, err
+			c.addDiagnostic(field.Type.Pos(), err.Error())
+			continue
+		}
+		if field.Tag != nil {
+			if err := checkTag(field.Tag.Value); err != nil {
+				c.addDiagnostic(field.Tag.Pos(), err.Error())
+			}
 		}
 		tag := parseTag(field.Tag)
 		isInline := name == ""
@@ -59,17 +70,19 @@ func (c *astParser) parseFields(fields []*ast.Field) ([]SpecMember, error) {
 			var err error
 			name, err = c.getInlineName(tp)
 			if err != nil {
-				return nil, err
+				c.addDiagnostic(field.Type.Pos(), err.Error())
+				continue
 			}
 		}
 		members = append(members, SpecMember{
-			Name:     name,
-			Type:     stringExpr,
-			Expr:     tp,
-			Tag:      tag,
-			Comments: comments,
-			Docs:     docs,
-			IsInline: isInline,
+			Annotations: c.parseAnnotations(field.Doc),
+			Name:        name,
+			Type:        stringExpr,
+			Expr:        tp,
+			Tag:         tag,
+			Comments:    comments,
+			Docs:        docs,
+			IsInline:    isInline,
 		})
 
 	}
@@ -1,15 +1,27 @@
 package parser
 
 import (
+	"io/ioutil"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 )
 
+func readDSL(t *testing.T, filename string) string {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read %s error: %s", filename, err)
+	}
+	return string(content)
+}
+
 func Test_astParser_parserStruct(t *testing.T) {
-	ast, _ := AstParserBuild(UserOption{
-		ScaffoldDSLContent: "testdata/user/ego.go",
+	ast, err := AstParserBuild(UserOption{
+		ScaffoldDSLContent: readDSL(t, "testdata/user/ego.go"),
 	}, TmplOption{})
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
 	if len(ast.modelArr) != 1 {
 		t.Fatalf("got %d model arr, want 1", len(ast.modelArr))
 	}
@@ -17,11 +29,214 @@ func Test_astParser_parserStruct(t *testing.T) {
 }
 
 func Test_astParser_parserStructTag(t *testing.T) {
-	ast, _ := AstParserBuild(UserOption{
-		ScaffoldDSLContent: "testdata/user/ego.go",
+	ast, err := AstParserBuild(UserOption{
+		ScaffoldDSLContent: readDSL(t, "testdata/user/ego.go"),
 	}, TmplOption{})
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
 	if len(ast.modelArr) != 1 {
 		t.Fatalf("got %d model arr, want 1", len(ast.modelArr))
 	}
 	spew.Dump(ast.modelArr)
 }
+
+func TestCheckDSL(t *testing.T) {
+	diagnostics := CheckDSL(`package egoctl
+
+type User struct {
+	Uid    int
+	Ch     chan int
+	Group  Group
+	Name   string ` + "`json:name`" + `
+}
+`)
+	want := []Diagnostic{
+		{Line: 5, Column: 9},
+		{Line: 6, Column: 9},
+		{Line: 7, Column: 16},
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics %v, want %d", len(diagnostics), diagnostics, len(want))
+	}
+	for i, value := range want {
+		if diagnostics[i].Line != value.Line || diagnostics[i].Column != value.Column {
+			t.Fatalf("got diagnostic %v, want line %d column %d", diagnostics[i], value.Line, value.Column)
+		}
+	}
+
+	diagnostics = CheckDSL("package egoctl\n\ntype User struct {\n\tUid int\n")
+	if len(diagnostics) == 0 || diagnostics[0].Line != 4 {
+		t.Fatalf("got diagnostics %v, want syntax error at line 4", diagnostics)
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	ast, err := AstParserBuild(UserOption{
+		ScaffoldDSLContent: `package egoctl
+
+// User 用户
+// @table(name=users, softDelete=true)
+// @index(columns="uid,user_name", unique=true)
+// @index(columns=user_name)
+type User struct {
+	Uid int ` + "`json:\"id\"`" + `
+	// @search(op=like)
+	// @label "昵称"
+	UserName string ` + "`json:\"userName\"`" + `
+}
+`,
+	}, TmplOption{})
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	user := ast.modelArr[0]
+	if len(user.Docs) != 4 || len(user.Annotations) != 3 {
+		t.Fatalf("got docs %v annotations %v, want 4 docs and 3 annotations", user.Docs, user.Annotations)
+	}
+	table := user.Annotations[0]
+	if table.Name != "table" || table.Properties["name"] != "users" || table.Properties["softDelete"] != "true" {
+		t.Fatalf("got table annotation %+v", table)
+	}
+
+	renderInfos := ast.GetRenderInfos(Descriptor{})
+	indexes := renderInfos[0].AnnotationList
+	if len(indexes) != 3 || indexes[1].Properties["columns"] != "uid,user_name" || indexes[1].Properties["unique"] != "true" ||
+		indexes[2].Properties["columns"] != "user_name" {
+		t.Fatalf("got annotation list %+v", indexes)
+	}
+
+	schemas := user.ToModelInfos()
+	annotations := schemas[1].FieldAnnotations
+	if annotations["search"].Properties["op"] != "like" {
+		t.Fatalf("got search annotation %+v", annotations["search"])
+	}
+	if annotations["label"].Value != "昵称" {
+		t.Fatalf("got label annotation %+v", annotations["label"])
+	}
+
+	diagnostics := CheckDSL("package egoctl\n\n// @table(name=users\ntype User struct {\n\tUid int\n}\n")
+	if len(diagnostics) != 1 || diagnostics[0].Line != 3 {
+		t.Fatalf("got diagnostics %v, want one at line 3", diagnostics)
+	}
+}
+
+func TestParseAnnotationQuoted(t *testing.T) {
+	annotation, err := parseAnnotation(`@enum(values="a, b", label='x=y', "c, d")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annotation.Properties["values"] != "a, b" || annotation.Properties["label"] != "x=y" || annotation.Value != "c, d" {
+		t.Fatalf("got %+v", annotation)
+	}
+	if _, err = parseAnnotation(`@enum(values="a, b)`); err == nil {
+		t.Fatal("want error for unterminated quote")
+	}
+}
+
+func TestRelations(t *testing.T) {
+	ast, err := AstParserBuild(UserOption{
+		ScaffoldDSLContent: `package egoctl
+
+type User struct {
+	Uid     int      ` + "`ego:\"primary_key\"`" + `
+	Profile Profile
+	Orders  []Order
+	Roles   []Role   ` + "`ego:\"many2many=user_roles\"`" + `
+}
+
+type Profile struct {
+	Id     int
+	UserId int
+}
+
+type Order struct {
+	Id        int
+	UserId    int
+	CreatorId int
+	Creator   *User ` + "`ego:\"fk=CreatorId\"`" + `
+}
+
+type Role struct {
+	Id int
+}
+`,
+	}, TmplOption{})
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	want := map[string]ModelRelation{
+		"Profile": {Kind: RelationHasOne, FieldName: "Profile", Model: "User", RefModel: "Profile", ForeignKey: "UserId", References: "Uid"},
+		"Orders":  {Kind: RelationHasMany, FieldName: "Orders", Model: "User", RefModel: "Order", ForeignKey: "UserId", References: "Uid"},
+		"Roles":   {Kind: RelationManyToMany, FieldName: "Roles", Model: "User", RefModel: "Role", ForeignKey: "UserId", References: "RoleId", JoinTable: "user_roles"},
+		"Creator": {Kind: RelationBelongsTo, FieldName: "Creator", Model: "Order", RefModel: "User", ForeignKey: "CreatorId", References: "Uid"},
+	}
+	got := append(ast.relations.Of("User"), ast.relations.Of("Order")...)
+	if len(got) != len(want) {
+		t.Fatalf("got %d relations %+v, want %d", len(got), got, len(want))
+	}
+	for _, relation := range got {
+		if relation != want[relation.FieldName] {
+			t.Fatalf("got relation %+v, want %+v", relation, want[relation.FieldName])
+		}
+	}
+
+	infos := ast.GetRenderInfos(Descriptor{})
+	for _, info := range infos {
+		if info.ModelName != "User" {
+			continue
+		}
+		if len(info.Relations.ByKind(RelationHasMany)) != 1 || info.Content[2].FieldRelation == nil {
+			t.Fatalf("got render info relations %+v", info.Relations)
+		}
+	}
+}
+
+func TestDescriptorWhen(t *testing.T) {
+	ast, err := AstParserBuild(UserOption{
+		Language: "Go",
+		ScaffoldDSLContent: `package egoctl
+
+type User struct {
+	Id     int    ` + "`ego:\"primary_key\"`" + `
+	Avatar string ` + "`ego:\"file\"`" + `
+}
+
+type Order struct {
+	Id    int
+	Cover string ` + "`ego:\"image;file\"`" + `
+}
+`,
+	}, TmplOption{})
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	cases := []struct {
+		when string
+		want map[string]bool
+	}{
+		{when: "", want: map[string]bool{"User": true, "Order": true}},
+		{when: `modelSchemas|fieldsTagExist:"ego,file"`, want: map[string]bool{"User": true, "Order": false}},
+		{when: `modelSchemas|fieldsTagValueExist:"ego,file"`, want: map[string]bool{"User": true, "Order": true}},
+		{when: `language == "React"`, want: map[string]bool{"User": false, "Order": false}},
+		{when: `modelName == "order"`, want: map[string]bool{"User": false, "Order": true}},
+	}
+	for _, c := range cases {
+		for _, info := range ast.GetRenderInfos(Descriptor{DstPath: "/tmp/egoctl/{$modelName$}.go", When: c.when}) {
+			enabled, err := NewRender(info).Enabled()
+			if err != nil {
+				t.Fatalf("when %q: %s", c.when, err)
+			}
+			if enabled != c.want[info.ModelName] {
+				t.Fatalf("when %q model %s: got %v, want %v", c.when, info.ModelName, enabled, c.want[info.ModelName])
+			}
+		}
+	}
+
+	_, err = NewRender(RenderInfo{ModelName: "User", Descriptor: Descriptor{DstPath: "/tmp/egoctl/a.go", When: "language =="}}).Enabled()
+	if err == nil {
+		t.Fatal("want error for invalid when expression")
+	}
+}
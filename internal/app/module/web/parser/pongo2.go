@@ -15,8 +15,27 @@ func init() {
 	_ = pongo2.RegisterFilter("camelString", pongo2CamelString)
 	_ = pongo2.RegisterFilter("fieldsGetPrimaryKey", pongo2ModelFieldsGetPrimaryKey) // 根据字段数组获取主键
 	_ = pongo2.RegisterFilter("fieldsExist", pongo2ModelFieldsExist)
-	_ = pongo2.RegisterFilter("fieldsTagExist", pongo2ModelFieldsTagExist) // models|fieldsTagExist:"ant,select"
+	_ = pongo2.RegisterFilter("fieldsTagExist", pongo2ModelFieldsTagExist)           // models|fieldsTagExist:"ant,select"
+	_ = pongo2.RegisterFilter("fieldsTagValueExist", pongo2ModelFieldsTagValueExist) // models|fieldsTagValueExist:"ego,file"
 	_ = pongo2.RegisterFilter("fieldGetTag", pongo2ModelFieldGetTag)
+	_ = pongo2.RegisterFilter("relationsOf", pongo2RelationsOf)         // modelGraph|relationsOf:"User"
+	_ = pongo2.RegisterFilter("relationsByKind", pongo2RelationsByKind) // modelRelations|relationsByKind:"hasMany"
+}
+
+func pongo2RelationsOf(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	graph, flag := in.Interface().(RelationGraph)
+	if !flag {
+		return pongo2.AsValue(ModelRelations{}), nil
+	}
+	return pongo2.AsValue(graph.Of(param.String())), nil
+}
+
+func pongo2RelationsByKind(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	relations, flag := in.Interface().(ModelRelations)
+	if !flag {
+		return pongo2.AsValue(ModelRelations{}), nil
+	}
+	return pongo2.AsValue(relations.ByKind(param.String())), nil
 }
 
 func pongo2ModelFieldsExist(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
@@ -56,6 +75,28 @@ func pongo2ModelFieldsTagExist(in *pongo2.Value, param *pongo2.Value) (*pongo2.V
 	return pongo2.AsSafeValue(false), nil
 }
 
+// pongo2ModelFieldsTagValueExist 与fieldsTagExist相同，但匹配tag中以;分隔的单个值，例如 ego:"primary_key;file" 中的file
+func pongo2ModelFieldsTagValueExist(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	arr, flag := in.Interface().(ModelSchemas)
+	if !flag {
+		return pongo2.AsSafeValue(false), nil
+	}
+
+	key, value, _ := strings.Cut(param.String(), ",")
+	for _, info := range arr {
+		tagValue, flag := info.FieldTags[key]
+		if !flag {
+			continue
+		}
+		for _, v := range tagValue.Value {
+			if strings.TrimSpace(v) == value {
+				return pongo2.AsSafeValue(true), nil
+			}
+		}
+	}
+	return pongo2.AsSafeValue(false), nil
+}
+
 func pongo2ModelFieldGetTag(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
 	info, flag := in.Interface().(ModelSchema)
 	if !flag {
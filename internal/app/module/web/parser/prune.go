@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gotomicro/egoctl/internal/diff"
+	"github.com/gotomicro/egoctl/internal/utils"
+)
+
+// pruneFiles 对比当前模板上一次的生成清单，找出本次不再生成的文件，其他绑定的模板生成的文件不受影响。
+// 没有被手动修改过的文件为obsolete，开启Prune时删除；手动修改过的文件为orphaned，始终保留。
+func (c *Container) pruneFiles() {
+	if c.err != nil || c.UserOption.Mode == "json" {
+		return
+	}
+	previous, err := ReadManifest(c.UserOption.ProjectPath, c.UserOption.Binding)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	produced := make(map[string]struct{})
+	for _, change := range c.Changes {
+		produced[relativePath(c.UserOption.ProjectPath, change.Path)] = struct{}{}
+	}
+
+	for _, item := range previous.Files {
+		if _, ok := produced[item.Path]; ok {
+			continue
+		}
+		filename := filepath.Join(c.UserOption.ProjectPath, filepath.FromSlash(item.Path))
+		if !utils.IsExist(filename) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			c.err = fmt.Errorf("读取生成文件失败, err: %w", err)
+			return
+		}
+		change := FileChange{
+			Path:      filename,
+			SrcName:   item.SrcName,
+			ModelName: item.ModelName,
+			Status:    FileStatusObsolete,
+			Diff:      diff.Unified(filename, filename, string(content), "", 3),
+			Hash:      ContentHash(content),
+		}
+		if change.Hash != item.Hash {
+			change.Status = FileStatusOrphaned
+		} else if c.UserOption.Prune && !c.UserOption.DryRun {
+			err = removeGenerated(c.UserOption.ProjectPath, filename)
+			if err != nil {
+				c.err = fmt.Errorf("删除生成文件失败, err: %w", err)
+				return
+			}
+			change.Status = FileStatusRemoved
+		}
+		c.Changes = append(c.Changes, change)
+	}
+}
+
+// removeGenerated 将生成文件移动到bak目录，并删除对应的基线
+func removeGenerated(projectPath string, filename string) error {
+	filePathBak := filepath.Join(filepath.Dir(filename), "bak")
+	err := createPath(filePathBak)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(filename, bakFileName(filePathBak, filename))
+	if err != nil {
+		return err
+	}
+	if basePath, ok := baselinePath(projectPath, filename); ok && utils.IsExist(basePath) {
+		return os.Remove(basePath)
+	}
+	return nil
+}
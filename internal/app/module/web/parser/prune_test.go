@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	pruneUserDSL      = "package egoctl\n\ntype User struct {\n\tId int\n}\n"
+	pruneUserOrderDSL = "package egoctl\n\ntype User struct {\n\tId int\n}\n\ntype Order struct {\n\tId int\n}\n"
+)
+
+// runPruneProject 用dsl在projectPath中生成代码
+func runPruneProject(t *testing.T, root string, dsl string, prune bool, dryRun bool) []FileChange {
+	t.Helper()
+	container := NewParser(UserOption{
+		Language:           "Go",
+		ScaffoldDSLContent: dsl,
+		ProType:            "ego-gin",
+		ProjectPath:        filepath.Join(root, "project"),
+		GitLocalPath:       filepath.Join(root, "tmpl"),
+		Path:               map[string]string{"backend": "."},
+		Prune:              prune,
+		DryRun:             dryRun,
+	})
+	container.CurPath = filepath.Join(root, "project")
+	if err := container.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return container.GetChanges()
+}
+
+func TestPruneFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		edit       string // 第二次生成前写入order.go的内容，为空时不修改
+		remove     bool   // 第二次生成前删除order.go
+		prune      bool
+		dryRun     bool
+		wantStatus string // order.go的状态，为空时不应该出现在结果中
+		wantExist  bool   // 第二次生成后order.go是否还在
+		wantBak    bool   // order.go是否被备份到bak目录
+	}{
+		{name: "obsolete without prune", wantStatus: FileStatusObsolete, wantExist: true},
+		{name: "removed with prune", prune: true, wantStatus: FileStatusRemoved, wantBak: true},
+		{name: "obsolete in dry run", prune: true, dryRun: true, wantStatus: FileStatusObsolete, wantExist: true},
+		{name: "orphaned when edited", edit: "package model\n\n// hand written\n", prune: true, wantStatus: FileStatusOrphaned, wantExist: true},
+		{name: "already deleted", remove: true, prune: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			projectPath := filepath.Join(root, "project")
+			orderFile := filepath.Join(projectPath, "model", "order.go")
+			writeFiles(t, root, map[string]string{
+				"tmpl/ego-gin/egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+`,
+				"tmpl/ego-gin/ego/model.go.tmpl": "package model\n\n// {$ modelName $}\n",
+				"project/go.mod":                 "module example.com/project\n\ngo 1.16\n",
+			})
+			runPruneProject(t, root, pruneUserOrderDSL, false, false)
+			if tt.edit != "" {
+				if err := ioutil.WriteFile(orderFile, []byte(tt.edit), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.remove {
+				if err := os.Remove(orderFile); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			changes := runPruneProject(t, root, pruneUserDSL, tt.prune, tt.dryRun)
+			status := ""
+			for _, change := range changes {
+				switch relativePath(projectPath, change.Path) {
+				case "model/user.go":
+					if change.Status != FileStatusUnchanged {
+						t.Errorf("user.go: got status %s, want %s", change.Status, FileStatusUnchanged)
+					}
+				case "model/order.go":
+					status = change.Status
+				default:
+					t.Errorf("unexpected change %+v", change)
+				}
+			}
+			if status != tt.wantStatus {
+				t.Errorf("order.go: got status %q, want %q", status, tt.wantStatus)
+			}
+			if _, err := os.Stat(orderFile); (err == nil) != tt.wantExist {
+				t.Errorf("order.go: got exist %v, want %v", err == nil, tt.wantExist)
+			}
+			if tt.edit != "" {
+				content, _ := ioutil.ReadFile(orderFile)
+				if string(content) != tt.edit {
+					t.Errorf("edited order.go was changed: %q", content)
+				}
+			}
+
+			baks, _ := ioutil.ReadDir(filepath.Join(projectPath, "model", "bak"))
+			hasBak := len(baks) == 1 && strings.HasPrefix(baks[0].Name(), "order.go.")
+			if hasBak != tt.wantBak {
+				t.Errorf("got bak %v, want %v", baks, tt.wantBak)
+			}
+			if tt.wantBak {
+				basePath, _ := baselinePath(projectPath, orderFile)
+				if _, err := os.Stat(basePath); !os.IsNotExist(err) {
+					t.Errorf("baseline of removed file not deleted")
+				}
+			}
+
+			// 删除的和已经不存在的文件从清单中移除，没有删除的继续保留在清单中，下次仍然会检查
+			manifest, err := ReadManifest(projectPath, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, inManifest := manifest.Find("model/order.go")
+			wantInManifest := tt.wantStatus == FileStatusObsolete || tt.wantStatus == FileStatusOrphaned
+			if inManifest != wantInManifest {
+				t.Errorf("order.go in manifest: got %v, want %v", inManifest, wantInManifest)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/gotomicro/egoctl/internal/utils"
+)
+
+const (
+	RelationHasOne     = "hasOne"
+	RelationHasMany    = "hasMany"
+	RelationBelongsTo  = "belongsTo"
+	RelationManyToMany = "manyToMany"
+)
+
+// ModelRelation 模型之间的关联关系，通过字段类型引用DSL中的其他模型，以及ego tag确定
+//
+//	Orders  []Order `ego:"fk=UserId"`             // hasMany，外键在Order上
+//	Profile Profile                                // hasOne，Profile上有UserId字段
+//	User    User    `ego:"fk=CreatorId"`          // belongsTo，外键在当前模型上
+//	Roles   []Role  `ego:"many2many=user_roles"`  // manyToMany
+type ModelRelation struct {
+	Kind       string `json:"kind"`       // hasOne, hasMany, belongsTo, manyToMany
+	FieldName  string `json:"fieldName"`  // 当前模型中的关联字段
+	Model      string `json:"model"`      // 当前模型
+	RefModel   string `json:"refModel"`   // 关联的模型
+	ForeignKey string `json:"foreignKey"` // 外键字段，manyToMany时为中间表中当前模型的外键
+	References string `json:"references"` // 外键引用的字段，manyToMany时为中间表中关联模型的外键
+	JoinTable  string `json:"joinTable"`  // manyToMany的中间表
+}
+
+type ModelRelations []ModelRelation
+
+// RelationGraph 所有模型的关联关系，key为模型名称
+type RelationGraph map[string]ModelRelations
+
+// buildRelations 根据所有模型构建关联关系，找不到外键的字段不作为关联
+func buildRelations(models []SpecType) RelationGraph {
+	modelM := make(map[string]SpecType, len(models))
+	for _, model := range models {
+		modelM[model.Name] = model
+	}
+
+	graph := make(RelationGraph, len(models))
+	for _, model := range models {
+		relations := make(ModelRelations, 0)
+		for _, member := range model.Members {
+			if member.IsInline {
+				continue
+			}
+			refName, isSlice := refModelName(member.Expr)
+			refModel, ok := modelM[refName]
+			if !ok {
+				continue
+			}
+			if relation, ok := resolveRelation(model, member, refModel, isSlice); ok {
+				relations = append(relations, relation)
+			}
+		}
+		graph[model.Name] = relations
+	}
+	return graph
+}
+
+func resolveRelation(model SpecType, member SpecMember, refModel SpecType, isSlice bool) (ModelRelation, bool) {
+	options := relationOptions(member)
+	relation := ModelRelation{
+		FieldName: member.Name,
+		Model:     model.Name,
+		RefModel:  refModel.Name,
+	}
+
+	if joinTable, ok := options["many2many"]; ok && isSlice {
+		relation.Kind = RelationManyToMany
+		relation.JoinTable = joinTable
+		if relation.JoinTable == "" {
+			relation.JoinTable = utils.SnakeString(model.Name) + "_" + utils.SnakeString(refModel.Name)
+		}
+		relation.ForeignKey = defaultString(options["fk"], model.Name+"Id")
+		relation.References = defaultString(options["references"], refModel.Name+"Id")
+		return relation, true
+	}
+
+	if isSlice {
+		// hasMany，外键在关联模型上
+		fk, ok := findField(refModel, defaultString(options["fk"], model.Name+"Id"))
+		if !ok {
+			return relation, false
+		}
+		relation.Kind = RelationHasMany
+		relation.ForeignKey = fk
+		relation.References = defaultString(options["references"], model.PrimaryKey())
+		return relation, true
+	}
+
+	// belongsTo，外键在当前模型上；指定了fk时优先在当前模型上查找
+	if fk, ok := findField(model, defaultString(options["fk"], member.Name+"Id")); ok {
+		relation.Kind = RelationBelongsTo
+		relation.ForeignKey = fk
+		relation.References = defaultString(options["references"], refModel.PrimaryKey())
+		return relation, true
+	}
+	// hasOne，外键在关联模型上
+	if fk, ok := findField(refModel, defaultString(options["fk"], model.Name+"Id")); ok {
+		relation.Kind = RelationHasOne
+		relation.ForeignKey = fk
+		relation.References = defaultString(options["references"], model.PrimaryKey())
+		return relation, true
+	}
+	return relation, false
+}
+
+// refModelName 获取字段引用的模型名称，支持 T、*T、[]T、[]*T
+func refModelName(expr interface{}) (string, bool) {
+	switch v := expr.(type) {
+	case *SpecStructType:
+		return strings.TrimPrefix(v.StringExpr, "*"), false
+	case *SpecType:
+		return v.Name, false
+	case *SpecPointerType:
+		name, isSlice := refModelName(v.Star)
+		if isSlice {
+			return "", false
+		}
+		return name, false
+	case *SpecArrayType:
+		name, isSlice := refModelName(v.ArrayType)
+		if isSlice {
+			return "", false
+		}
+		return name, true
+	default:
+		return "", false
+	}
+}
+
+// relationOptions 解析ego tag中key=value形式的关联配置
+func relationOptions(member SpecMember) map[string]string {
+	options := make(map[string]string)
+	for _, tag := range member.Tag.Value {
+		if tag.Name != "ego" {
+			continue
+		}
+		for _, value := range tag.Value {
+			kv := strings.SplitN(strings.TrimSpace(value), "=", 2)
+			if len(kv) == 2 {
+				options[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+			} else if kv[0] == "many2many" {
+				options["many2many"] = ""
+			}
+		}
+	}
+	return options
+}
+
+// findField 在模型中查找字段，不区分大小写，例如 UserId 可以匹配 UserID
+func findField(model SpecType, name string) (string, bool) {
+	for _, member := range model.Members {
+		if strings.EqualFold(member.Name, name) {
+			return member.Name, true
+		}
+	}
+	return "", false
+}
+
+// PrimaryKey 获取模型主键，与fieldsGetPrimaryKey一致，默认为Id
+func (model SpecType) PrimaryKey() string {
+	for _, member := range model.Members {
+		for _, tag := range member.Tag.Value {
+			if tag.Name != "ego" {
+				continue
+			}
+			for _, value := range tag.Value {
+				if value == "primary_key" {
+					return member.Name
+				}
+			}
+		}
+	}
+	return "Id"
+}
+
+func defaultString(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// Of 获取模型的关联关系
+func (g RelationGraph) Of(modelName string) ModelRelations {
+	relations, ok := g[modelName]
+	if !ok {
+		return ModelRelations{}
+	}
+	return relations
+}
+
+// ByKind 按关联类型过滤
+func (r ModelRelations) ByKind(kind string) ModelRelations {
+	output := make(ModelRelations, 0)
+	for _, relation := range r {
+		if relation.Kind == kind {
+			output = append(output, relation)
+		}
+	}
+	return output
+}
@@ -7,12 +7,14 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
 	"github.com/gotomicro/egoctl/internal/app/module/web/parser/pongo2"
 	"github.com/gotomicro/egoctl/internal/app/module/web/parser/pongo2render"
+	"github.com/gotomicro/egoctl/internal/diff"
 	"github.com/gotomicro/egoctl/internal/logger"
 	"github.com/gotomicro/egoctl/internal/system"
 	"github.com/gotomicro/egoctl/internal/utils"
@@ -55,11 +57,6 @@ func NewRender(m RenderInfo) *RenderFile {
 	// new render
 	obj.Render = pongo2render.NewRender(path.Join(obj.Option.GitLocalPath, obj.Option.ProType, m.TmplPath))
 
-	filePath := path.Dir(obj.FlushFile)
-	err := createPath(filePath)
-	if err != nil {
-		logger.Log.Fatalf("Could not create the controllers directory: %s", err)
-	}
 	// get go package path
 	if m.Option.Language == constx.LanguageGo {
 		obj.PkgPath = getPackagePath(m.Option.ProjectPath)
@@ -92,12 +89,17 @@ func NewRender(m RenderInfo) *RenderFile {
 	obj.SetContext("packageMod", obj.PkgPath)
 
 	obj.SetContext("modelSchemas", modelSchemas)
+	obj.SetContext("modelAnnotations", m.Annotations)
+	obj.SetContext("modelAnnotationList", m.AnnotationList)
+	obj.SetContext("modelRelations", m.Relations)
+	obj.SetContext("modelGraph", m.Graph)
 
 	for key, value := range pathCtx {
 		obj.SetContext(key, value)
 	}
 
 	obj.SetContext("apiPrefix", obj.Option.ApiPrefix)
+	obj.SetContext("variables", obj.Option.Variables)
 	obj.SetContext("generateTime", obj.GenerateTime)
 
 	if obj.Option.ContextDebug {
@@ -110,18 +112,41 @@ func (r *RenderFile) SetContext(key string, value interface{}) {
 	r.Context[key] = value
 }
 
-func (r *RenderFile) Exec(name string) error {
-	var (
-		buf string
-		err error
-	)
+// Enabled 根据描述的when表达式判断是否生成该文件，表达式使用与模板相同的上下文
+func (r *RenderFile) Enabled() (bool, error) {
+	if strings.TrimSpace(r.Descriptor.When) == "" {
+		return true, nil
+	}
+	tpl, err := pongo2.FromString("{% if " + r.Descriptor.When + " %}true{% endif %}")
+	if err != nil {
+		return false, fmt.Errorf("解析%s的when表达式失败, err: %w", r.Descriptor.SrcName, err)
+	}
+	result, err := tpl.Execute(r.Context)
+	if err != nil {
+		return false, fmt.Errorf("执行%s的when表达式失败, err: %w", r.Descriptor.SrcName, err)
+	}
+	return result == "true", nil
+}
+
+func (r *RenderFile) Exec(name string) (change FileChange, err error) {
+	var buf string
+	change = FileChange{
+		Path:      r.FlushFile,
+		SrcName:   name,
+		ModelName: r.ModelName,
+	}
+	// 只生成一次的文件不属于某个模型
+	if r.Descriptor.Once {
+		change.ModelName = ""
+	}
 	buf, err = r.Render.Template(name).Execute(r.Context)
 	if err != nil {
-		return fmt.Errorf("Could not create the %s render tmpl , err: %w", name, err)
+		return change, fmt.Errorf("Could not create the %s render tmpl , err: %w", name, err)
 	}
 	_, err = os.Stat(r.Descriptor.DstPath)
 	var orgContent []byte
-	if err == nil {
+	isExist := err == nil
+	if isExist {
 		if org, err := os.OpenFile(r.Descriptor.DstPath, os.O_RDONLY, 0666); err == nil {
 			orgContent, _ = ioutil.ReadAll(org)
 			org.Close()
@@ -141,13 +166,67 @@ func (r *RenderFile) Exec(name string) error {
 		}
 		output = bts
 	}
+	// 模板渲染的原始内容，作为下一次三方合并的基线
+	pristine := output
+	change.Generated = pristine
+	// 保留原文件中的手写代码区域
+	output, change.DroppedRegions = SpliceCustomRegions(orgContent, output, GetSeg(ext))
+	baseContent, hasBase := readBaseline(r.Option.ProjectPath, r.FlushFile)
+	// 不允许覆盖的文件即使有生成基线也不合并
+	protected := isExist && !isNeedOverwrite(r.FlushFile)
+
+	if protected {
+		change.Status = FileStatusProtected
+	} else if isExist && hasBase && FileContentChange(baseContent, orgContent, GetSeg(ext)) {
+		// 用户修改过上一次生成的文件，做三方合并
+		merged, conflicts := diff.Merge3(string(baseContent), string(orgContent), string(output))
+		output = []byte(merged)
+		change.Conflicts = conflicts
+		change.Status = FileStatusMerged
+		if conflicts > 0 {
+			change.Status = FileStatusConflict
+		}
+	}
 
-	if FileContentChange(orgContent, output, GetSeg(ext)) {
-		err = r.write(r.FlushFile, output)
-		if err != nil {
-			return fmt.Errorf("创建文件失败, err: %w", err)
+	switch {
+	case !isExist:
+		change.Status = FileStatusNew
+	case !FileContentChange(orgContent, output, GetSeg(ext)):
+		change.Status = FileStatusUnchanged
+		change.Hash = ContentHash(orgContent)
+		if !r.Option.DryRun {
+			err = writeBaseline(r.Option.ProjectPath, r.FlushFile, pristine)
+			if err != nil {
+				return change, fmt.Errorf("记录生成基线失败, err: %w", err)
+			}
 		}
-		elog.Info("create file", elog.String("packageName", r.PackageName), elog.String("flushFile", r.FlushFile))
+		return change, nil
+	case change.Status != "":
+		// 不允许覆盖，或者已经做过三方合并
+	default:
+		change.Status = FileStatusChanged
+	}
+	// 新模板去掉了有内容的手写代码区域，覆盖会丢失这些代码，作为冲突由用户处理，不写入文件
+	if len(change.DroppedRegions) > 0 && change.Status != FileStatusProtected {
+		change.Status = FileStatusConflict
+		change.Conflicts = len(change.DroppedRegions)
+	}
+	change.Diff = diff.Unified(r.FlushFile, r.FlushFile, string(orgContent), string(output), 3)
+	if change.Status != FileStatusProtected && len(change.DroppedRegions) == 0 {
+		change.Hash = ContentHash(output)
+	}
+
+	if r.Option.DryRun || change.Status == FileStatusProtected || len(change.DroppedRegions) > 0 {
+		return change, nil
+	}
+	err = r.write(r.FlushFile, output)
+	if err != nil {
+		return change, fmt.Errorf("创建文件失败, err: %w", err)
+	}
+	err = writeBaseline(r.Option.ProjectPath, r.FlushFile, pristine)
+	if err != nil {
+		return change, fmt.Errorf("记录生成基线失败, err: %w", err)
 	}
-	return nil
+	elog.Info("create file", elog.String("packageName", r.PackageName), elog.String("flushFile", r.FlushFile))
+	return change, nil
 }
@@ -20,28 +20,33 @@ type Container struct {
 	TmplOption       TmplOption             // tmpl option
 	CurPath          string                 // user current path
 	EnableModules    map[string]interface{} // beego pro provider a collection of module
-	FunctionOnce     map[string]sync.Once   // exec function once
+	FunctionOnce     map[string]*sync.Once  // exec function once
 	GenerateTime     string
 	GenerateTimeUnix int64
 	Timestamp        Timestamp
 	parser           *astParser
 	err              error
 	StoreData        StoreData
+	Changes          []FileChange // 每个目标文件的生成结果
 }
 
 // user option
 type UserOption struct {
-	Mode               string            `json:"mode"` // mode: tmpl 模板，json json数据
-	ContextDebug       bool              `json:"contextDebug"`
-	ScaffoldDSLContent string            `json:"scaffoldDslContent"`
-	Language           string            `json:"language"`
-	ProType            string            `json:"proType"`
-	ApiPrefix          string            `json:"apiPrefix"`
-	EnableModule       []string          `json:"enableModule"`
-	ProjectPath        string            `json:"projectPath"`
-	GitLocalPath       string            `json:"gitLocalPath"`
-	EnableFormat       bool              `json:"enableFormat"`
-	Path               map[string]string `json:"path"`
+	Mode               string                 `json:"mode"` // mode: tmpl 模板，json json数据
+	ContextDebug       bool                   `json:"contextDebug"`
+	ScaffoldDSLContent string                 `json:"scaffoldDslContent"`
+	Language           string                 `json:"language"`
+	ProType            string                 `json:"proType"`
+	ApiPrefix          string                 `json:"apiPrefix"`
+	EnableModule       []string               `json:"enableModule"`
+	ProjectPath        string                 `json:"projectPath"`
+	GitLocalPath       string                 `json:"gitLocalPath"`
+	EnableFormat       bool                   `json:"enableFormat"`
+	Path               map[string]string      `json:"path"`
+	DryRun             bool                   `json:"dryRun"`    // 只在内存中渲染，返回文件变更，不写入磁盘
+	Prune              bool                   `json:"prune"`     // 删除不再生成且没有被手动修改过的文件
+	Variables          map[string]interface{} `json:"variables"` // 用户填写的模板变量
+	Binding            string                 `json:"binding"`   // 项目绑定多个模板时当前模板在Path中的key，用于区分生成清单，主模板为空
 }
 
 type StoreData struct {
@@ -53,8 +58,10 @@ type StoreData struct {
 
 // tmpl option
 type TmplOption struct {
-	RenderPath string       `toml:"renderPath" json:"renderPath"`
-	Descriptor []Descriptor `json:"descriptor"`
+	RenderPath string         `toml:"renderPath" json:"renderPath"`
+	Descriptor []Descriptor   `json:"descriptor"`
+	Modules    []TmplModule   `toml:"module" json:"modules"`      // 模板声明的模块，descriptor通过module归属于某个模块
+	Variables  []TmplVariable `toml:"variables" json:"variables"` // 模板声明的变量
 }
 
 type Descriptor struct {
@@ -63,36 +70,19 @@ type Descriptor struct {
 	DstPath string `toml:"dstPath" json:"dstPath"`
 	Once    bool   `toml:"once" json:"once"`
 	Script  string `toml:"script" json:"script"`
+	// 生成条件，pongo2表达式，为空时总是生成，例如 language == "React"、modelSchemas|fieldsTagValueExist:"ego,file"
+	When string `toml:"when" json:"when"`
 }
 
 func (descriptor Descriptor) Parse(option UserOption, modelName string, modelNames []string, paths map[string]string) (newDescriptor Descriptor, ctx pongo2.Context) {
 	var (
 		err             error
 		relativeDstPath string
-		absFile         string
-		relPath         string
 	)
 
 	newDescriptor = descriptor
 	render := pongo2render.NewRender("")
-	ctx = make(pongo2.Context)
-	for key, value := range paths {
-		absFile, err = filepath.Abs(value)
-		if err != nil {
-			logger.Log.Fatalf("absolute path error %s from key %s and value %s", err, key, value)
-		}
-		relPath, err = filepath.Rel(system.CurrentDir, absFile)
-		if err != nil {
-			logger.Log.Fatalf("Could not get the relative path: %s", err)
-		}
-		// user input path
-		ctx["path"+utils.CamelCase(key)] = option.ProjectPath + "/" + value
-		// relativePath
-		ctx["pathRel"+utils.CamelCase(key)] = relPath
-	}
-	ctx["modelName"] = lowerFirst(utils.CamelString(modelName))
-	ctx["modelNames"] = modelNames
-	ctx["modelNameSnake"] = utils.SnakeString(modelName)
+	ctx = descriptorContext(option, modelName, modelNames, paths)
 	relativeDstPath, err = render.TemplateFromString(descriptor.DstPath).Execute(ctx)
 	if err != nil {
 		logger.Log.Fatalf("egoctl tmpl exec error, err: %s", err)
@@ -110,6 +100,32 @@ func (descriptor Descriptor) Parse(option UserOption, modelName string, modelNam
 	return
 }
 
+// descriptorContext DstPath、Script使用的上下文，模板渲染时也会合并到模板的上下文中
+func descriptorContext(option UserOption, modelName string, modelNames []string, paths map[string]string) pongo2.Context {
+	ctx := make(pongo2.Context)
+	for key, value := range paths {
+		absFile, err := filepath.Abs(value)
+		if err != nil {
+			logger.Log.Fatalf("absolute path error %s from key %s and value %s", err, key, value)
+		}
+		relPath, err := filepath.Rel(system.CurrentDir, absFile)
+		if err != nil {
+			logger.Log.Fatalf("Could not get the relative path: %s", err)
+		}
+		// user input path
+		ctx["path"+utils.CamelCase(key)] = option.ProjectPath + "/" + value
+		// relativePath
+		ctx["pathRel"+utils.CamelCase(key)] = relPath
+	}
+	ctx["modelName"] = lowerFirst(utils.CamelString(modelName))
+	ctx["modelNames"] = modelNames
+	ctx["modelNameSnake"] = utils.SnakeString(modelName)
+	ctx["language"] = option.Language
+	ctx["proType"] = option.ProType
+	ctx["variables"] = option.Variables
+	return ctx
+}
+
 func (descriptor Descriptor) IsExistScript() bool {
 	return descriptor.Script != ""
 }
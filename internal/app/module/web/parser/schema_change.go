@@ -0,0 +1,26 @@
+package parser
+
+const (
+	FileStatusNew       = "new"       // 文件不存在，将会创建
+	FileStatusChanged   = "changed"   // 文件内容有变化，将会覆盖
+	FileStatusUnchanged = "unchanged" // 文件内容没有变化
+	FileStatusProtected = "protected" // 文件内容有变化，但没有开启@EgoctlOverwrite，不会覆盖
+	FileStatusMerged    = "merged"    // 文件被手动修改过，和新生成的内容自动合并
+	FileStatusConflict  = "conflict"  // 文件被手动修改过，合并有冲突，写入了冲突标记；或新模板去掉了有内容的手写代码区域，没有覆盖
+	FileStatusObsolete  = "obsolete"  // 不再生成的文件，没有被手动修改过，开启prune时会删除
+	FileStatusOrphaned  = "orphaned"  // 不再生成的文件，被手动修改过，不会删除
+	FileStatusRemoved   = "removed"   // 不再生成的文件，已删除
+)
+
+// FileChange 单个目标文件的生成结果
+type FileChange struct {
+	Path           string   `json:"path"`           // 目标文件路径
+	SrcName        string   `json:"srcName"`        // 模板文件名称
+	ModelName      string   `json:"modelName"`      // 模型名称
+	Status         string   `json:"status"`         // new, changed, unchanged, protected
+	Diff           string   `json:"diff"`           // 与当前文件内容的unified diff
+	Conflicts      int      `json:"conflicts"`      // 三方合并的冲突数量
+	Hash           string   `json:"hash"`           // 写入后文件内容的md5，protected文件为空
+	DroppedRegions []string `json:"droppedRegions"` // 新模板中已经没有的手写代码区域，不为空时状态为conflict，不会覆盖文件
+	Generated      []byte   `json:"-"`              // 模板渲染的原始内容，不包含手写代码和合并结果
+}
@@ -6,6 +6,12 @@ type ModelSchema struct {
 	FieldType    string             `json:"goType"`    // go type
 	FieldTags    map[string]SpecTag `json:"fieldTags"` // map[gorm]{name:"gorm",origin:"not null;comment:"名称""}
 	FieldComment string             `json:"comment"`   // mysql comment
+	// 字段注解，例如 // @search(op=like) 为 map[search]{Name:"search",Properties:{op:"like"}}
+	FieldAnnotations map[string]SpecAnnotation `json:"annotations"`
+	// 字段按顺序排列的所有注解，包括重复的注解
+	FieldAnnotationList []SpecAnnotation `json:"annotationList"`
+	// 字段关联的模型，不是关联字段时为nil
+	FieldRelation *ModelRelation `json:"relation"`
 }
 
 type ModelSchemas []ModelSchema
@@ -1,12 +1,16 @@
 package parser
 
 type RenderInfo struct {
-	ModelNames   []string     `json:"modelNames"` // 所有model names
-	ModelName    string       `json:"modelName"`  // 当前model names
-	Module       string       `json:"-"`
-	TmplPath     string       `json:"tmplPath"`
-	GenerateTime string       `json:"generateTime"`
-	Option       UserOption   `json:"-"`
-	Content      ModelSchemas `json:"content"`
-	Descriptor   Descriptor   `json:"-"`
+	ModelNames     []string                  `json:"modelNames"`     // 所有model names
+	ModelName      string                    `json:"modelName"`      // 当前model names
+	Annotations    map[string]SpecAnnotation `json:"annotations"`    // 当前model的注解，同名的注解以最后一个为准
+	AnnotationList []SpecAnnotation          `json:"annotationList"` // 当前model按顺序排列的所有注解，包括重复的注解
+	Relations      ModelRelations            `json:"relations"`      // 当前model的关联关系
+	Graph          RelationGraph             `json:"-"`              // 所有model的关联关系
+	Module         string                    `json:"-"`
+	TmplPath       string                    `json:"tmplPath"`
+	GenerateTime   string                    `json:"generateTime"`
+	Option         UserOption                `json:"-"`
+	Content        ModelSchemas              `json:"content"`
+	Descriptor     Descriptor                `json:"-"`
 }
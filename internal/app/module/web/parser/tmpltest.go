@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
+	"github.com/gotomicro/egoctl/internal/diff"
+	"github.com/gotomicro/egoctl/internal/utils"
+	"github.com/pelletier/go-toml"
+)
+
+const (
+	TemplateTestDir    = "testdata"  // 模板类型目录下的测试用例目录，每个子目录是一个用例
+	TemplateTestDSL    = "dsl.go"    // 用例的DSL
+	TemplateTestOption = "case.toml" // 用例的配置，可选
+	TemplateTestGolden = "golden"    // 期望生成的文件
+
+	TemplateDiffAdded   = "added"   // 生成了golden中没有的文件
+	TemplateDiffMissing = "missing" // golden中的文件没有生成
+	TemplateDiffChanged = "changed" // 生成的内容与golden不一致
+
+	// 固定生成时间，模板中使用generateTime时golden保持稳定
+	templateTestTime = "20060102_150405"
+)
+
+// TemplateCase 测试用例的配置
+//
+//	language = "Go"
+//	apiPrefix = "/api"
+//	enableModule = ["upload"]
+//	[variables]
+//	port = 9001
+type TemplateCase struct {
+	Language     string                 `toml:"language"`
+	ApiPrefix    string                 `toml:"apiPrefix"`
+	EnableFormat bool                   `toml:"enableFormat"`
+	EnableModule []string               `toml:"enableModule"`
+	Variables    map[string]interface{} `toml:"variables"`
+}
+
+// TemplateCaseResult 测试用例的结果
+type TemplateCaseResult struct {
+	Name    string             `json:"name"`
+	Err     error              `json:"-"`
+	Diffs   []TemplateCaseDiff `json:"diffs"`   // 与golden不一致的文件
+	Updated bool               `json:"updated"` // 使用生成的结果更新了golden
+}
+
+// Passed 生成成功并且与golden一致
+func (r TemplateCaseResult) Passed() bool {
+	return r.Err == nil && len(r.Diffs) == 0
+}
+
+// TemplateCaseDiff 与golden不一致的文件
+type TemplateCaseDiff struct {
+	Path   string `json:"path"` // 相对项目目录的路径
+	Status string `json:"status"`
+	Diff   string `json:"diff"`
+}
+
+// RunTemplateTests 运行模板类型目录下testdata中的所有用例：用用例的dsl.go在临时目录中生成项目，
+// 与用例的golden目录比较，update为true时用生成的结果覆盖golden
+func RunTemplateTests(dir string, update bool) ([]TemplateCaseResult, error) {
+	testDir := filepath.Join(dir, TemplateTestDir)
+	files, err := ioutil.ReadDir(testDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取测试用例目录失败, err: %w", err)
+	}
+	results := make([]TemplateCaseResult, 0)
+	for _, file := range files {
+		if !file.IsDir() || !utils.IsExist(filepath.Join(testDir, file.Name(), TemplateTestDSL)) {
+			continue
+		}
+		results = append(results, runTemplateCase(dir, filepath.Join(testDir, file.Name()), update))
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%s下没有包含%s的测试用例", testDir, TemplateTestDSL)
+	}
+	return results, nil
+}
+
+func runTemplateCase(dir string, caseDir string, update bool) TemplateCaseResult {
+	result := TemplateCaseResult{Name: filepath.Base(caseDir), Diffs: make([]TemplateCaseDiff, 0)}
+	projectPath, err := ioutil.TempDir("", "egoctl-template-test")
+	if err != nil {
+		result.Err = fmt.Errorf("创建临时目录失败, err: %w", err)
+		return result
+	}
+	defer os.RemoveAll(projectPath)
+
+	generated, err := generateTemplateCase(dir, caseDir, projectPath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	goldenPath := filepath.Join(caseDir, TemplateTestGolden)
+	if update {
+		if err = os.RemoveAll(goldenPath); err != nil {
+			result.Err = fmt.Errorf("清空golden目录失败, err: %w", err)
+			return result
+		}
+		result.Err = writeTree(goldenPath, generated)
+		result.Updated = result.Err == nil
+		return result
+	}
+	golden := make(map[string][]byte)
+	if utils.IsDir(goldenPath) {
+		golden, err = readTree(goldenPath, nil)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	result.Diffs = compareTree(golden, generated)
+	return result
+}
+
+// generateTemplateCase 在projectPath中生成用例，返回生成的文件，不包含.egoctl元数据和没有变化的初始文件
+func generateTemplateCase(dir string, caseDir string, projectPath string) (generated map[string][]byte, err error) {
+	option := TemplateCase{}
+	optionFile := filepath.Join(caseDir, TemplateTestOption)
+	if utils.IsExist(optionFile) {
+		tree, err := toml.LoadFile(optionFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取%s失败, err: %w", TemplateTestOption, err)
+		}
+		if err = tree.Unmarshal(&option); err != nil {
+			return nil, fmt.Errorf("解析%s失败, err: %w", TemplateTestOption, err)
+		}
+	}
+	if option.Language == "" {
+		option.Language = constx.LanguageGo
+	}
+	dslContent, err := ioutil.ReadFile(filepath.Join(caseDir, TemplateTestDSL))
+	if err != nil {
+		return nil, fmt.Errorf("读取%s失败, err: %w", TemplateTestDSL, err)
+	}
+
+	// Go项目需要go.mod，用例中没有时使用以用例名为模块名的go.mod
+	seeds := make(map[string][]byte)
+	if option.Language == constx.LanguageGo {
+		goMod, err := ioutil.ReadFile(filepath.Join(caseDir, "go.mod"))
+		if err != nil {
+			goMod = []byte(fmt.Sprintf("module example.com/%s\n\ngo 1.16\n", filepath.Base(caseDir)))
+		}
+		seeds["go.mod"] = goMod
+	}
+	if err = writeTree(projectPath, seeds); err != nil {
+		return nil, err
+	}
+
+	container := NewParser(UserOption{
+		Language:           option.Language,
+		ScaffoldDSLContent: string(dslContent),
+		ProType:            filepath.Base(dir),
+		ApiPrefix:          option.ApiPrefix,
+		EnableModule:       option.EnableModule,
+		Variables:          option.Variables,
+		ProjectPath:        projectPath,
+		GitLocalPath:       filepath.Dir(dir),
+		EnableFormat:       option.EnableFormat,
+		Path: map[string]string{
+			"backend": ".",
+		},
+	})
+	container.CurPath = projectPath
+	container.GenerateTime = templateTestTime
+	container.GenerateTimeUnix = 0
+	// pongo2render在模板错误时会panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("生成代码失败: %v", r)
+		}
+	}()
+	if err = container.Run(); err != nil {
+		return nil, fmt.Errorf("生成代码失败: %w", err)
+	}
+	return readTree(projectPath, seeds)
+}
+
+// readTree 读取目录下的所有文件，key为以/分隔的相对路径，跳过.egoctl目录和内容与seeds相同的文件
+func readTree(root string, seeds map[string][]byte) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.Walk(root, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == EgoctlDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if seed, ok := seeds[rel]; ok && bytes.Equal(seed, content) {
+			return nil
+		}
+		files[rel] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取目录%s失败, err: %w", root, err)
+	}
+	return files, nil
+}
+
+// writeTree 写入所有文件
+func writeTree(root string, files map[string][]byte) error {
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := createPath(filepath.Dir(filename)); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filename, content, 0644); err != nil {
+			return fmt.Errorf("写入文件%s失败, err: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// compareTree 按路径排序比较golden和生成的文件
+func compareTree(golden map[string][]byte, generated map[string][]byte) []TemplateCaseDiff {
+	names := make([]string, 0, len(golden)+len(generated))
+	for name := range golden {
+		names = append(names, name)
+	}
+	for name := range generated {
+		if _, ok := golden[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := make([]TemplateCaseDiff, 0)
+	for _, name := range names {
+		want, inGolden := golden[name]
+		got, isGenerated := generated[name]
+		switch {
+		case !inGolden:
+			diffs = append(diffs, TemplateCaseDiff{Path: name, Status: TemplateDiffAdded, Diff: diff.Unified("/dev/null", name, "", string(got), 3)})
+		case !isGenerated:
+			diffs = append(diffs, TemplateCaseDiff{Path: name, Status: TemplateDiffMissing, Diff: diff.Unified(name, "/dev/null", string(want), "", 3)})
+		case !bytes.Equal(want, got):
+			diffs = append(diffs, TemplateCaseDiff{Path: name, Status: TemplateDiffChanged, Diff: diff.Unified(path.Join(TemplateTestGolden, name), name, string(want), string(got), 3)})
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunTemplateTests(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ego-gin")
+	files := map[string]string{
+		"egoctl.toml": `renderPath = "ego"
+
+[[descriptor]]
+srcName = "model.go.tmpl"
+dstPath = "{$ pathBackend $}/model/{$ modelName $}.go"
+`,
+		"ego/model.go.tmpl":        "package model\n\n// {$ modelName $} {$ generateTime $}\n",
+		"testdata/basic/dsl.go":    "package egoctl\n\ntype User struct {\n\tId int\n}\n",
+		"testdata/basic/case.toml": "language = \"Go\"\n",
+	}
+	for name, content := range files {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := RunTemplateTests(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Err != nil || !results[0].Updated {
+		t.Fatalf("update: got %+v", results)
+	}
+	golden, err := ioutil.ReadFile(filepath.Join(dir, "testdata/basic/golden/model/user.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(golden) != "package model\n\n// user 20060102_150405\n" {
+		t.Fatalf("got golden %q", golden)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "testdata/basic/golden/go.mod")); !os.IsNotExist(err) {
+		t.Fatal("want seeded go.mod excluded from golden")
+	}
+
+	results, err = RunTemplateTests(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Passed() {
+		t.Fatalf("want passed, got %+v", results[0])
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "ego/model.go.tmpl"), []byte("package models\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "testdata/basic/golden/stale.go"), []byte("package stale\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err = RunTemplateTests(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs := results[0].Diffs
+	if len(diffs) != 2 || diffs[0].Path != "model/user.go" || diffs[0].Status != TemplateDiffChanged ||
+		diffs[1].Path != "stale.go" || diffs[1].Status != TemplateDiffMissing {
+		t.Fatalf("got diffs %+v", diffs)
+	}
+}
@@ -113,6 +113,55 @@ func (tag StructTag) Lookup() []SpecTag {
 	return output
 }
 
+// checkTag 检查tag是否符合 key:"value" 格式，与go vet的structtag检查一致
+func checkTag(value string) error {
+	tag, err := strconv.Unquote(value)
+	if err != nil {
+		return fmt.Errorf("struct tag %s is not a string literal", value)
+	}
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return fmt.Errorf("struct tag %s: bad syntax for struct tag key", value)
+		}
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return fmt.Errorf("struct tag %s: bad syntax for struct tag pair", value)
+		}
+		if tag[i+1] != '"' {
+			return fmt.Errorf("struct tag %s: bad syntax for struct tag value", value)
+		}
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return fmt.Errorf("struct tag %s: bad syntax for struct tag value", value)
+		}
+		if _, err := strconv.Unquote(tag[:i+1]); err != nil {
+			return fmt.Errorf("struct tag %s: bad syntax for struct tag value", value)
+		}
+		tag = tag[i+1:]
+	}
+	return nil
+}
+
 // 需要trim `gorm:"not null;PRIMARY_KEY;comment:'用户uid'" json:"uid"`
 func parseLineTag(value string) []SpecTag {
 	value = strings.TrimSuffix(value, "`")
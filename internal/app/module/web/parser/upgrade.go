@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/gotomicro/egoctl/internal/diff"
+)
+
+// GeneratedDiff 两次生成结果之间单个文件的差异
+type GeneratedDiff struct {
+	Path      string `json:"path"`      // 相对项目目录的路径
+	ModelName string `json:"modelName"` // 模型名称
+	Status    string `json:"status"`    // new 只有新版本生成，removed 新版本不再生成，changed 内容不同
+	Diff      string `json:"diff"`      // 旧版本到新版本的unified diff
+}
+
+// DiffGenerated 比较同一个项目用两个模板版本生成的结果，只比较模板渲染的原始内容，与项目中现有的文件无关
+func DiffGenerated(projectPath string, from []FileChange, to []FileChange) []GeneratedDiff {
+	fromFiles := generatedFiles(projectPath, from)
+	toFiles := generatedFiles(projectPath, to)
+	paths := make([]string, 0, len(fromFiles)+len(toFiles))
+	for path := range fromFiles {
+		paths = append(paths, path)
+	}
+	for path := range toFiles {
+		if _, ok := fromFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	output := make([]GeneratedDiff, 0)
+	for _, path := range paths {
+		fromChange, inFrom := fromFiles[path]
+		toChange, inTo := toFiles[path]
+		switch {
+		case !inFrom:
+			output = append(output, GeneratedDiff{Path: path, ModelName: toChange.ModelName, Status: FileStatusNew, Diff: diff.Unified(path, path, "", string(toChange.Generated), 3)})
+		case !inTo:
+			output = append(output, GeneratedDiff{Path: path, ModelName: fromChange.ModelName, Status: FileStatusRemoved, Diff: diff.Unified(path, path, string(fromChange.Generated), "", 3)})
+		case !bytes.Equal(fromChange.Generated, toChange.Generated):
+			output = append(output, GeneratedDiff{Path: path, ModelName: toChange.ModelName, Status: FileStatusChanged, Diff: diff.Unified(path, path, string(fromChange.Generated), string(toChange.Generated), 3)})
+		}
+	}
+	return output
+}
+
+// generatedFiles 本次渲染的文件，key为相对项目目录的路径，不包含不再生成的文件
+func generatedFiles(projectPath string, changes []FileChange) map[string]FileChange {
+	files := make(map[string]FileChange)
+	for _, change := range changes {
+		switch change.Status {
+		case FileStatusObsolete, FileStatusOrphaned, FileStatusRemoved:
+			continue
+		}
+		files[relativePath(projectPath, change.Path)] = change
+	}
+	return files
+}
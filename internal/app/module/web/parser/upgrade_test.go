@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestDiffGenerated(t *testing.T) {
+	from := []FileChange{
+		{Path: "/app/model/user.go", ModelName: "User", Status: FileStatusUnchanged, Generated: []byte("package model\n")},
+		{Path: "/app/model/order.go", ModelName: "Order", Status: FileStatusChanged, Generated: []byte("package model\n\ntype Order struct{}\n")},
+		{Path: "/app/api/user.go", ModelName: "User", Status: FileStatusUnchanged, Generated: []byte("package api\n")},
+		{Path: "/app/old.go", Status: FileStatusObsolete},
+	}
+	to := []FileChange{
+		{Path: "/app/model/user.go", ModelName: "User", Status: FileStatusUnchanged, Generated: []byte("package model\n")},
+		{Path: "/app/model/order.go", ModelName: "Order", Status: FileStatusChanged, Generated: []byte("package model\n\ntype Order struct {\n\tId int\n}\n")},
+		{Path: "/app/router.go", Status: FileStatusNew, Generated: []byte("package app\n")},
+	}
+	diffs := DiffGenerated("/app", from, to)
+	want := []struct {
+		path   string
+		status string
+	}{
+		{path: "api/user.go", status: FileStatusRemoved},
+		{path: "model/order.go", status: FileStatusChanged},
+		{path: "router.go", status: FileStatusNew},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("got %+v", diffs)
+	}
+	for i, w := range want {
+		if diffs[i].Path != w.path || diffs[i].Status != w.status || diffs[i].Diff == "" {
+			t.Fatalf("diff %d: got %+v, want %+v", i, diffs[i], w)
+		}
+	}
+}
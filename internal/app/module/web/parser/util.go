@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,6 +21,8 @@ import (
 const (
 	AnnotationOverwrite    = "@EgoctlOverwrite"
 	AnnotationGenerateTime = "@EgoctlGenerateTime"
+	AnnotationCustomBegin  = "egoctl:begin custom" // 手写代码区域开始，后面跟区域id
+	AnnotationCustomEnd    = "egoctl:end"          // 手写代码区域结束
 )
 
 var CompareExcept = []string{AnnotationGenerateTime}
@@ -47,7 +50,7 @@ func (c *RenderFile) write(filename string, buf []byte) (err error) {
 	name := path.Base(filename)
 
 	if utils.IsExist(filename) {
-		bakName := fmt.Sprintf("%s/%s.%s.bak", filePathBak, filepath.Base(name), time.Now().Format("2006.01.02.15.04.05"))
+		bakName := bakFileName(filePathBak, name)
 		logger.Log.Infof("bak file '%s'", bakName)
 		if err := os.Rename(filename, bakName); err != nil {
 			err = errors.New("file is bak error, path is " + bakName)
@@ -75,6 +78,11 @@ func (c *RenderFile) write(filename string, buf []byte) (err error) {
 	return
 }
 
+// bakFileName 获取备份文件名称
+func bakFileName(filePathBak string, filename string) string {
+	return fmt.Sprintf("%s/%s.%s.bak", filePathBak, filepath.Base(filename), time.Now().Format("2006.01.02.15.04.05"))
+}
+
 func isNeedOverwrite(fileName string) (flag bool) {
 	seg := GetSeg(filepath.Ext(fileName))
 
@@ -95,13 +103,116 @@ func isNeedOverwrite(fileName string) (flag bool) {
 			overwrite = strings.TrimSpace(s[len(AnnotationOverwrite):])
 		}
 	}
-	if strings.ToLower(overwrite) == "yes" {
+	switch strings.ToLower(overwrite) {
+	case "yes":
+		flag = true
+		return
+	case "no":
+		// 明确声明不覆盖的文件，即使有手写代码区域也不覆盖
+		return
+	}
+	// 有手写代码区域的文件，重新生成时会保留区域内容，可以覆盖
+	if len(parseCustomRegions(string(contentByte), seg)) > 0 {
 		flag = true
 		return
 	}
 	return
 }
 
+// customRegionID 判断该行是否为手写代码区域的开始标记，返回区域id
+func customRegionID(line string, seg string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, seg) {
+		return "", false
+	}
+	line = strings.TrimSpace(strings.TrimPrefix(line, seg))
+	if !strings.HasPrefix(line, AnnotationCustomBegin) {
+		return "", false
+	}
+	id := strings.TrimSpace(line[len(AnnotationCustomBegin):])
+	return id, id != ""
+}
+
+// isCustomRegionEnd 判断该行是否为手写代码区域的结束标记
+func isCustomRegionEnd(line string, seg string) bool {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, seg) {
+		return false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, seg)) == AnnotationCustomEnd
+}
+
+// parseCustomRegions 读取文件中所有手写代码区域，key为区域id，value为区域内的行
+func parseCustomRegions(content string, seg string) map[string][]string {
+	regions := make(map[string][]string)
+	var (
+		id    string
+		lines []string
+		open  bool
+	)
+	for _, line := range strings.Split(content, "\n") {
+		if !open {
+			id, open = customRegionID(line, seg)
+			lines = make([]string, 0)
+			continue
+		}
+		if isCustomRegionEnd(line, seg) {
+			regions[id] = lines
+			open = false
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return regions
+}
+
+// SpliceCustomRegions 将原文件中手写代码区域的内容，放回到新渲染内容的同名区域中，
+// 返回新渲染内容中已经没有的、有内容的区域id，这些区域的内容无法保留
+func SpliceCustomRegions(org, rendered []byte, seg string) ([]byte, []string) {
+	regions := parseCustomRegions(string(org), seg)
+	if len(regions) == 0 {
+		return rendered, nil
+	}
+
+	renderedLines := strings.Split(string(rendered), "\n")
+	output := make([]string, 0, len(renderedLines))
+	used := make(map[string]struct{})
+	for i := 0; i < len(renderedLines); i++ {
+		line := renderedLines[i]
+		output = append(output, line)
+		id, ok := customRegionID(line, seg)
+		if !ok {
+			continue
+		}
+		custom, ok := regions[id]
+		if !ok {
+			continue
+		}
+		// 跳过模板里的默认内容，找不到结束标记则保留模板内容
+		end := i + 1
+		for end < len(renderedLines) && !isCustomRegionEnd(renderedLines[end], seg) {
+			end++
+		}
+		if end >= len(renderedLines) {
+			continue
+		}
+		output = append(output, custom...)
+		output = append(output, renderedLines[end])
+		used[id] = struct{}{}
+		i = end
+	}
+
+	dropped := make([]string, 0)
+	for id, lines := range regions {
+		if _, ok := used[id]; ok || strings.TrimSpace(strings.Join(lines, "")) == "" {
+			continue
+		}
+		dropped = append(dropped, id)
+	}
+	sort.Strings(dropped)
+	return []byte(strings.Join(output, "\n")), dropped
+}
+
 // createPath 调用os.MkdirAll递归创建文件夹
 func createPath(filePath string) error {
 	if !utils.IsExist(filePath) {
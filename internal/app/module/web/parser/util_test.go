@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpliceCustomRegions(t *testing.T) {
+	org := `package user
+
+// egoctl:begin custom imports
+import "fmt"
+// egoctl:end
+
+func Hello() {
+	// egoctl:begin custom hello
+	fmt.Println("hand written")
+	// egoctl:end
+}
+`
+	rendered := `package user
+
+// egoctl:begin custom imports
+// egoctl:end
+
+func Hello() {
+	// egoctl:begin custom hello
+	// TODO
+	// egoctl:end
+}
+
+func World() {}
+`
+	want := `package user
+
+// egoctl:begin custom imports
+import "fmt"
+// egoctl:end
+
+func Hello() {
+	// egoctl:begin custom hello
+	fmt.Println("hand written")
+	// egoctl:end
+}
+
+func World() {}
+`
+	got, dropped := SpliceCustomRegions([]byte(org), []byte(rendered), GetSeg(".go"))
+	if string(got) != want || len(dropped) != 0 {
+		t.Fatalf("got\n%s\ndropped %v, want\n%s", got, dropped, want)
+	}
+}
+
+func TestSpliceCustomRegionsSQL(t *testing.T) {
+	org := "-- egoctl:begin custom index\nCREATE INDEX idx_name ON user (name);\n-- egoctl:end\n"
+	rendered := "CREATE TABLE user (name varchar(32));\n-- egoctl:begin custom index\n-- egoctl:end\n"
+	want := "CREATE TABLE user (name varchar(32));\n-- egoctl:begin custom index\nCREATE INDEX idx_name ON user (name);\n-- egoctl:end\n"
+	got, dropped := SpliceCustomRegions([]byte(org), []byte(rendered), GetSeg(".sql"))
+	if string(got) != want || len(dropped) != 0 {
+		t.Fatalf("got\n%s\ndropped %v, want\n%s", got, dropped, want)
+	}
+}
+
+func TestSpliceCustomRegionsDropped(t *testing.T) {
+	org := "package user\n\n// egoctl:begin custom empty\n// egoctl:end\n\n// egoctl:begin custom hello\nfunc Hello() {}\n// egoctl:end\n"
+	rendered := "package user\n"
+	got, dropped := SpliceCustomRegions([]byte(org), []byte(rendered), GetSeg(".go"))
+	if string(got) != rendered {
+		t.Fatalf("got\n%s", got)
+	}
+	if len(dropped) != 1 || dropped[0] != "hello" {
+		t.Fatalf("got dropped %v, want [hello]", dropped)
+	}
+}
+
+func TestIsNeedOverwrite(t *testing.T) {
+	regions := "// egoctl:begin custom hello\nfunc Hello() {}\n// egoctl:end\n"
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "yes", content: "// @EgoctlOverwrite yes\npackage user\n", want: true},
+		{name: "none", content: "package user\n", want: false},
+		{name: "regions", content: "package user\n" + regions, want: true},
+		{name: "no with regions", content: "// @EgoctlOverwrite no\npackage user\n" + regions, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "user.go")
+			if err := ioutil.WriteFile(filename, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := isNeedOverwrite(filename); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
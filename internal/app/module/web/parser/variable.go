@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	VariableTypeString = "string"
+	VariableTypeInt    = "int"
+	VariableTypeBool   = "bool"
+)
+
+// TmplVariable 模板在egoctl.toml中声明的变量，由用户在项目中填写，模板中通过 {$ variables.name $} 使用
+//
+//	[[variables]]
+//	name = "port"
+//	type = "int"
+//	default = 9001
+//	help = "服务端口"
+type TmplVariable struct {
+	Name    string        `toml:"name" json:"name"`
+	Type    string        `toml:"type" json:"type"` // string、int、bool，默认为string
+	Default interface{}   `toml:"default" json:"default"`
+	Options []interface{} `toml:"options" json:"options"` // 可选值，为空时不限制
+	Help    string        `toml:"help" json:"help"`
+}
+
+// ValidateVariables 校验用户填写的变量并转换为声明的类型，模板中没有声明的变量会被忽略
+func (o TmplOption) ValidateVariables(values map[string]interface{}) (map[string]interface{}, error) {
+	output := make(map[string]interface{})
+	for _, variable := range o.Variables {
+		value, ok := values[variable.Name]
+		if !ok || value == nil {
+			continue
+		}
+		v, err := variable.convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("变量%s: %w", variable.Name, err)
+		}
+		output[variable.Name] = v
+	}
+	return output, nil
+}
+
+// ResolveVariables 校验用户填写的变量，没有填写的变量使用默认值
+func (o TmplOption) ResolveVariables(values map[string]interface{}) (map[string]interface{}, error) {
+	output, err := o.ValidateVariables(values)
+	if err != nil {
+		return nil, err
+	}
+	for _, variable := range o.Variables {
+		if _, ok := output[variable.Name]; ok {
+			continue
+		}
+		value := variable.Default
+		if value == nil {
+			value = variable.zero()
+		}
+		v, err := variable.convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("变量%s的默认值: %w", variable.Name, err)
+		}
+		output[variable.Name] = v
+	}
+	return output, nil
+}
+
+func (v TmplVariable) zero() interface{} {
+	switch v.Type {
+	case VariableTypeInt:
+		return int64(0)
+	case VariableTypeBool:
+		return false
+	}
+	return ""
+}
+
+// convert 转换为声明的类型，web表单和toml中的数字、字符串都可以转换，并检查是否为可选值
+func (v TmplVariable) convert(value interface{}) (interface{}, error) {
+	var output interface{}
+	switch v.Type {
+	case VariableTypeInt:
+		switch n := value.(type) {
+		case int64:
+			output = n
+		case int:
+			output = int64(n)
+		case float64:
+			if n != float64(int64(n)) {
+				return nil, fmt.Errorf("%v不是整数", value)
+			}
+			output = int64(n)
+		case string:
+			i, err := strconv.ParseInt(n, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s不是整数", n)
+			}
+			output = i
+		default:
+			return nil, fmt.Errorf("%v不是整数", value)
+		}
+	case VariableTypeBool:
+		switch b := value.(type) {
+		case bool:
+			output = b
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s不是布尔值", b)
+			}
+			output = parsed
+		default:
+			return nil, fmt.Errorf("%v不是布尔值", value)
+		}
+	case VariableTypeString, "":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v不是字符串", value)
+		}
+		output = s
+	default:
+		return nil, fmt.Errorf("不支持的变量类型%s", v.Type)
+	}
+
+	if len(v.Options) == 0 {
+		return output, nil
+	}
+	for _, option := range v.Options {
+		if fmt.Sprint(option) == fmt.Sprint(output) {
+			return output, nil
+		}
+	}
+	return nil, fmt.Errorf("%v不是可选值%v", output, v.Options)
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveVariables(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "ego-gin"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "ego-gin", "egoctl.toml"), []byte(`
+[[variables]]
+name = "serviceName"
+help = "服务名称"
+
+[[variables]]
+name = "port"
+type = "int"
+default = 9001
+
+[[variables]]
+name = "auth"
+type = "bool"
+default = true
+
+[[variables]]
+name = "license"
+default = "MIT"
+options = ["MIT", "Apache-2.0"]
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	option, err := LoadTmplOption(dir, "ego-gin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// web表单提交的JSON中数字为float64，命令行中为字符串
+	got, err := option.ResolveVariables(map[string]interface{}{"port": float64(8080), "auth": "false", "unknown": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"serviceName": "", "port": int64(8080), "auth": false, "license": "MIT"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+
+	for _, values := range []map[string]interface{}{
+		{"port": "abc"},
+		{"port": 1.5},
+		{"auth": 1},
+		{"license": "GPL"},
+		{"serviceName": 1},
+	} {
+		if _, err = option.ValidateVariables(values); err == nil {
+			t.Fatalf("want error for %v", values)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/gotomicro/egoctl/internal/app/module/web/template"
+)
+
+// PrimaryPathKey 项目主模板在UserOption.Path中的key，输出到项目目录
+const PrimaryPathKey = "backend"
+
+// TemplateBinding 项目绑定的模板，与主模板使用同一份DSL，生成到各自的目录
+type TemplateBinding struct {
+	PathKey       string                 `json:"pathKey"`       // UserOption.Path中的key，模板中通过pathXxx引用输出目录，例如frontend对应pathFrontend
+	SubPath       string                 `json:"subPath"`       // 相对项目目录的输出目录
+	GitRemotePath string                 `json:"gitRemotePath"` // 模板地址
+	ProType       string                 `json:"proType"`       // 模板类型
+	Language      string                 `json:"language"`      // Go React Vue 其他
+	EnableModule  []string               `json:"enableModule"`  // 开启模块
+	Variables     map[string]interface{} `json:"variables"`     // 模板变量的值
+	TemplateRef   string                 `json:"templateRef"`   // 模板版本：tag、分支或commit，为空时使用同步下来的最新代码
+}
+
+// 项目绑定的模板参数
+type InfoTemplates struct {
+	Path      string            `json:"path" binding:"required"`
+	Templates []TemplateBinding `json:"templates"`
+}
+
+// Bindings 项目绑定的所有模板，第一个为主模板
+func (i Info) Bindings() []TemplateBinding {
+	bindings := []TemplateBinding{{
+		PathKey:       PrimaryPathKey,
+		SubPath:       ".",
+		GitRemotePath: i.GitRemotePath,
+		ProType:       i.ProType,
+		Language:      i.Language,
+		EnableModule:  i.EnableModule,
+		Variables:     i.Variables,
+		TemplateRef:   i.TemplateRef,
+	}}
+	return append(bindings, i.Templates...)
+}
+
+// binding 按pathKey查找绑定的模板，pathKey为空时为主模板
+func (i Info) binding(pathKey string) (TemplateBinding, error) {
+	if pathKey == "" {
+		pathKey = PrimaryPathKey
+	}
+	for _, binding := range i.Bindings() {
+		if binding.PathKey == pathKey {
+			return binding, nil
+		}
+	}
+	return TemplateBinding{}, fmt.Errorf("项目没有绑定%s模板", pathKey)
+}
+
+// updateBinding 修改pathKey对应的绑定模板，主模板的配置保存在项目上
+func (i *Info) updateBinding(pathKey string, update func(binding *TemplateBinding)) error {
+	binding, err := i.binding(pathKey)
+	if err != nil {
+		return err
+	}
+	if binding.PathKey != PrimaryPathKey {
+		for j := range i.Templates {
+			if i.Templates[j].PathKey == binding.PathKey {
+				update(&i.Templates[j])
+			}
+		}
+		return nil
+	}
+	update(&binding)
+	i.GitRemotePath = binding.GitRemotePath
+	i.ProType = binding.ProType
+	i.Language = binding.Language
+	i.EnableModule = binding.EnableModule
+	i.Variables = binding.Variables
+	i.TemplateRef = binding.TemplateRef
+	return nil
+}
+
+// tmplOption 读取绑定模板在其版本下的模板类型配置
+func (b TemplateBinding) tmplOption() (option parser.TmplOption, err error) {
+	templateInfo, err := template.Srv.TemplateInfo(template.InfoUniqId{GitRemotePath: template.GitURL(b.GitRemotePath)})
+	if err != nil {
+		return option, fmt.Errorf("获取模板信息失败: %w", err)
+	}
+	gitLocalPath, err := templateInfo.RevisionPath(b.TemplateRef)
+	if err != nil {
+		return option, err
+	}
+	return parser.LoadTmplOption(gitLocalPath, b.ProType)
+}
+
+// Paths 所有绑定模板的输出目录，每个模板都可以引用其他模板的目录
+func (i Info) Paths() map[string]string {
+	paths := make(map[string]string)
+	for _, binding := range i.Bindings() {
+		paths[binding.PathKey] = binding.SubPath
+	}
+	return paths
+}
+
+// validateBindings 校验绑定的模板：key不能重复，输出目录必须在项目目录下，模板和模板类型必须存在，变量符合模板的声明
+func validateBindings(bindings []TemplateBinding) error {
+	keys := map[string]bool{PrimaryPathKey: true}
+	for i, binding := range bindings {
+		if binding.PathKey == "" {
+			return fmt.Errorf("第%d个模板缺少pathKey", i+1)
+		}
+		if keys[binding.PathKey] {
+			return fmt.Errorf("pathKey %s重复", binding.PathKey)
+		}
+		keys[binding.PathKey] = true
+		subPath := filepath.Clean(binding.SubPath)
+		if binding.SubPath == "" || filepath.IsAbs(subPath) || subPath == ".." || strings.HasPrefix(subPath, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("模板%s的输出目录%s必须是项目目录下的相对路径", binding.PathKey, binding.SubPath)
+		}
+		option, err := binding.tmplOption()
+		if err != nil {
+			return fmt.Errorf("模板%s: %w", binding.PathKey, err)
+		}
+		if _, err = option.ResolveVariables(binding.Variables); err != nil {
+			return fmt.Errorf("模板%s的变量错误: %w", binding.PathKey, err)
+		}
+	}
+	return nil
+}
+
+// ProjectUpdateTemplates 保存项目在主模板之外绑定的模板，没有指定版本的模板固定为当前的commit
+func (p *projectSrv) ProjectUpdateTemplates(req InfoTemplates) (err error) {
+	info, err := p.ProjectInfo(InfoUniqId{Path: req.Path})
+	if err != nil {
+		return fmt.Errorf("获取projects失败: %w", err)
+	}
+	for i := range req.Templates {
+		req.Templates[i].SubPath = filepath.ToSlash(filepath.Clean(req.Templates[i].SubPath))
+		// 请求中没有填写模块和变量时保留原来的值，和请求中的值一样按模板的声明校验
+		for _, prev := range info.Templates {
+			if prev.PathKey != req.Templates[i].PathKey {
+				continue
+			}
+			if req.Templates[i].EnableModule == nil {
+				req.Templates[i].EnableModule = prev.EnableModule
+			}
+			if req.Templates[i].Variables == nil {
+				req.Templates[i].Variables = prev.Variables
+			}
+		}
+		if req.Templates[i].TemplateRef != "" {
+			continue
+		}
+		templateInfo, err := template.Srv.TemplateInfo(template.InfoUniqId{GitRemotePath: template.GitURL(req.Templates[i].GitRemotePath)})
+		if err == nil {
+			req.Templates[i].TemplateRef = templateInfo.Version()
+		}
+	}
+	if err = validateBindings(req.Templates); err != nil {
+		return err
+	}
+
+	// 防止并发请求
+	p.l.Lock()
+	defer p.l.Unlock()
+	value, err := p.leveldb.Get([]byte(constx.LevelDBProjects), nil)
+	if err != nil {
+		return fmt.Errorf("获取projects失败: %w", err)
+	}
+	projectsList := make([]Info, 0)
+	err = json.Unmarshal(value, &projectsList)
+	if err != nil {
+		return fmt.Errorf("解析项目json失败: %w", err)
+	}
+	isExist := false
+	for i := range projectsList {
+		if projectsList[i].Path == req.Path {
+			isExist = true
+			projectsList[i].Templates = req.Templates
+			projectsList[i].Utime = time.Now().Unix()
+		}
+	}
+	if !isExist {
+		return fmt.Errorf("不存在该项目数据")
+	}
+	jsonBytes, err := json.Marshal(projectsList)
+	if err != nil {
+		return fmt.Errorf("JSON编码失败: %w", err)
+	}
+	err = p.leveldb.Put([]byte(constx.LevelDBProjects), jsonBytes, nil)
+	if err != nil {
+		return fmt.Errorf("写入leveldb失败: %w", err)
+	}
+	return nil
+}
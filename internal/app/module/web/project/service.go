@@ -8,22 +8,26 @@ import (
 	"time"
 
 	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
+	"github.com/gotomicro/egoctl/internal/app/module/web/exporter"
 	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
 	"github.com/gotomicro/egoctl/internal/app/module/web/template"
 	"github.com/syndtr/goleveldb/leveldb"
 )
 
 type Info struct {
-	Name          string   `json:"name" binding:"required"`
-	Path          string   `json:"path" binding:"required"`
-	GitRemotePath string   `json:"gitRemotePath" binding:"required"`
-	ProType       string   `json:"proType"`      // 默认类型
-	Language      string   `json:"language"`     // Go React Vue 其他
-	ApiPrefix     string   `json:"apiPrefix"`    // API 前缀
-	DSL           string   `json:"dsl"`          // dsl 描述
-	EnableModule  []string `json:"enableModule"` // 开启模块
-	Ctime         int64    `json:"ctime"`
-	Utime         int64    `json:"utime"`
+	Name          string                 `json:"name" binding:"required"`
+	Path          string                 `json:"path" binding:"required"`
+	GitRemotePath string                 `json:"gitRemotePath" binding:"required"`
+	ProType       string                 `json:"proType"`      // 默认类型
+	Language      string                 `json:"language"`     // Go React Vue 其他
+	ApiPrefix     string                 `json:"apiPrefix"`    // API 前缀
+	DSL           string                 `json:"dsl"`          // dsl 描述
+	EnableModule  []string               `json:"enableModule"` // 开启模块
+	Variables     map[string]interface{} `json:"variables"`    // 模板变量的值
+	TemplateRef   string                 `json:"templateRef"`  // 模板版本：tag、分支或commit，为空时使用同步下来的最新代码
+	Templates     []TemplateBinding      `json:"templates"`    // 主模板之外绑定的模板，例如前端模板
+	Ctime         int64                  `json:"ctime"`
+	Utime         int64                  `json:"utime"`
 }
 
 type InfoDSL struct {
@@ -33,22 +37,78 @@ type InfoDSL struct {
 
 // 用户看到的列表数据
 type InfoDto struct {
-	Name          string `json:"name" binding:"required"`          // 名称
-	GitRemotePath string `json:"gitRemotePath" binding:"required"` // 远程地址
-	Path          string `json:"path"`                             // 存储路径
-	TemplateName  string `json:"templateName"`                     // 模板名称
-	ProType       string `json:"proType"`                          // 默认类型
-	Language      string `json:"language"`                         // Go React Vue 其他
-	ApiPrefix     string `json:"apiPrefix"`                        // API 前缀
-	DSL           string `json:"dsl"`                              // dsl 描述
-	Ctime         int64  `json:"ctime"`
-	Utime         int64  `json:"utime"`
+	Name          string            `json:"name" binding:"required"`          // 名称
+	GitRemotePath string            `json:"gitRemotePath" binding:"required"` // 远程地址
+	Path          string            `json:"path"`                             // 存储路径
+	TemplateName  string            `json:"templateName"`                     // 模板名称
+	ProType       string            `json:"proType"`                          // 默认类型
+	Language      string            `json:"language"`                         // Go React Vue 其他
+	ApiPrefix     string            `json:"apiPrefix"`                        // API 前缀
+	DSL           string            `json:"dsl"`                              // dsl 描述
+	EnableModule  []string          `json:"enableModule"`                     // 开启模块
+	TemplateRef   string            `json:"templateRef"`                      // 模板版本
+	Templates     []TemplateBinding `json:"templates"`                        // 主模板之外绑定的模板
+	Ctime         int64             `json:"ctime"`
+	Utime         int64             `json:"utime"`
 }
 
 type InfoUniqId struct {
 	Path string `json:"path" form:"path"`
 }
 
+// 生成代码参数
+type InfoGen struct {
+	Path  string `json:"path" form:"path"`
+	Prune bool   `json:"prune" form:"prune"` // 删除DSL中已移除模型对应的生成文件
+}
+
+// 绑定模板参数
+type InfoBinding struct {
+	Path    string `json:"path" form:"path"`
+	PathKey string `json:"pathKey" form:"pathKey"` // 绑定模板的key，为空时为主模板
+}
+
+// 模板变量参数
+type InfoVariables struct {
+	Path      string                 `json:"path" binding:"required"`
+	PathKey   string                 `json:"pathKey"` // 绑定模板的key，为空时为主模板
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// 项目的模板变量，包括模板声明的变量和已经填写的值
+type VariablesDto struct {
+	Variables []parser.TmplVariable  `json:"variables"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+// 模板版本参数
+type InfoTemplateRef struct {
+	Path        string `json:"path" form:"path" binding:"required"`
+	PathKey     string `json:"pathKey" form:"pathKey"`         // 绑定模板的key，为空时为主模板
+	TemplateRef string `json:"templateRef" form:"templateRef"` // tag、分支或commit，为空时使用同步下来的最新代码
+}
+
+// 模板升级预览参数
+type InfoUpgradePreview struct {
+	Path        string `json:"path" form:"path" binding:"required"`
+	PathKey     string `json:"pathKey" form:"pathKey"`         // 绑定模板的key，为空时预览主模板
+	TemplateRef string `json:"templateRef" form:"templateRef"` // 候选的模板版本，为空时使用同步下来的最新代码
+}
+
+// 模板升级预览
+type UpgradePreview struct {
+	PathKey string                 `json:"pathKey"` // 预览的绑定模板
+	FromRef string                 `json:"fromRef"` // 项目当前的模板版本
+	ToRef   string                 `json:"toRef"`   // 候选的模板版本
+	Files   []parser.GeneratedDiff `json:"files"`   // 生成结果有差异的文件
+}
+
+// 导出DSL模型参数
+type InfoExport struct {
+	Path   string `json:"path" form:"path"`
+	Format string `json:"format" form:"format"` // jsonschema或openapi，默认为openapi
+}
+
 type Infos []Info
 
 func (i Infos) ToInfoDtos() []InfoDto {
@@ -65,6 +125,9 @@ func (i Infos) ToInfoDtos() []InfoDto {
 			ProType:       value.ProType,
 			ApiPrefix:     value.ApiPrefix,
 			DSL:           value.DSL,
+			EnableModule:  value.EnableModule,
+			TemplateRef:   value.TemplateRef,
+			Templates:     value.Templates,
 			Language:      value.Language,
 		})
 	}
@@ -103,6 +166,14 @@ func (p *projectSrv) ProjectList() (list []InfoDto, err error) {
 }
 
 func (p *projectSrv) ProjectCreate(req Info) (err error) {
+	// 没有指定模板版本时固定为模板当前的commit，之后同步模板不会影响该项目
+	if req.TemplateRef == "" {
+		templateInfo, err := template.Srv.TemplateInfo(template.InfoUniqId{GitRemotePath: template.GitURL(req.GitRemotePath)})
+		if err == nil {
+			req.TemplateRef = templateInfo.Version()
+		}
+	}
+
 	// 防止并发请求
 	p.l.Lock()
 	defer p.l.Unlock()
@@ -187,12 +258,21 @@ func (p *projectSrv) ProjectUpdate(req Info) (err error) {
 	listNew := make([]Info, 0)
 	for _, value := range projectsList {
 		if value.Path == req.Path {
+			// 更换模板时，原来的模板版本不再适用，固定为新模板当前的commit
+			if value.GitRemotePath != req.GitRemotePath {
+				value.TemplateRef = ""
+				templateInfo, err := template.Srv.TemplateInfo(template.InfoUniqId{GitRemotePath: template.GitURL(req.GitRemotePath)})
+				if err == nil {
+					value.TemplateRef = templateInfo.Version()
+				}
+			}
 			value.Name = req.Name
 			value.GitRemotePath = req.GitRemotePath
 			value.Utime = time.Now().Unix()
 			value.ApiPrefix = req.ApiPrefix
 			value.ProType = req.ProType
 			value.Language = req.Language
+			value.EnableModule = req.EnableModule
 		}
 		listNew = append(listNew, value)
 	}
@@ -212,6 +292,14 @@ func (p *projectSrv) ProjectUpdate(req Info) (err error) {
 }
 
 func (p *projectSrv) ProjectDSL(req InfoDSL) (err error) {
+	// DSL有错误时不保存，返回所有错误的行列信息
+	if req.DSL != "" {
+		diagnostics := parser.CheckDSL(req.DSL)
+		if len(diagnostics) > 0 {
+			return fmt.Errorf("DSL解析失败: %w", diagnostics)
+		}
+	}
+
 	// 防止并发请求
 	p.l.Lock()
 	defer p.l.Unlock()
@@ -302,64 +390,182 @@ func (t *projectSrv) ProjectInfo(info InfoUniqId) (resp Info, err error) {
 	return
 }
 
-func (p *projectSrv) ProjectGen(req InfoUniqId) (err error) {
-	// 防止并发请求
+// userOption 根据项目信息和模板信息构造生成代码的用户配置
+func (p *projectSrv) userOption(req InfoUniqId) (option parser.UserOption, err error) {
 	info, err := p.ProjectInfo(req)
 	if err != nil {
-		return fmt.Errorf("获取projects失败: %w", err)
+		return option, fmt.Errorf("获取projects失败: %w", err)
+	}
+	return p.bindingOption(info, info.Bindings()[0])
+}
+
+// userOptions 项目绑定的每个模板的用户配置，第一个为主模板
+func (p *projectSrv) userOptions(req InfoUniqId) (options []parser.UserOption, err error) {
+	info, err := p.ProjectInfo(req)
+	if err != nil {
+		return nil, fmt.Errorf("获取projects失败: %w", err)
+	}
+	for _, binding := range info.Bindings() {
+		option, err := p.bindingOption(info, binding)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
 	}
+	return options, nil
+}
 
-	templateInfo, err := template.Srv.TemplateInfo(template.InfoUniqId{GitRemotePath: template.GitURL(info.GitRemotePath)})
+// bindingOption 使用项目的DSL和绑定的模板构造生成代码的用户配置
+func (p *projectSrv) bindingOption(info Info, binding TemplateBinding) (option parser.UserOption, err error) {
+	templateInfo, err := template.Srv.TemplateInfo(template.InfoUniqId{GitRemotePath: template.GitURL(binding.GitRemotePath)})
+	if err != nil {
+		return option, fmt.Errorf("获取模板信息失败: %w", err)
+	}
+	gitLocalPath, err := templateInfo.RevisionPath(binding.TemplateRef)
 	if err != nil {
-		return fmt.Errorf("获取模板信息失败: %w", err)
+		return option, err
 	}
-	parserObj := parser.NewParser(parser.UserOption{
-		Language:           info.Language,
+	option = parser.UserOption{
+		Language:           binding.Language,
 		ScaffoldDSLContent: info.DSL,
-		ProType:            info.ProType,
+		ProType:            binding.ProType,
 		ApiPrefix:          info.ApiPrefix,
-		EnableModule:       make([]string, 0),
+		EnableModule:       binding.EnableModule,
+		Variables:          binding.Variables,
 		ProjectPath:        info.Path,
-		GitLocalPath:       templateInfo.Path,
+		GitLocalPath:       gitLocalPath,
 		EnableFormat:       false,
-		Path: map[string]string{
-			"backend": ".",
-		},
-	})
+		Path:               info.Paths(),
+	}
+	if binding.PathKey != PrimaryPathKey {
+		option.Binding = binding.PathKey
+	}
+	return option, nil
+}
 
-	err = parserObj.Run()
+// runOptions 依次使用每个模板生成代码，所有模板使用相同的生成时间，返回所有文件的生成结果。
+// 写入文件前先对所有模板做一次预览，任意模板失败时不写入任何文件；出错时同时返回已经得到的生成结果
+func runOptions(options []parser.UserOption) (resp []parser.FileChange, err error) {
+	if len(options) > 1 && !options[0].DryRun {
+		dryRun := make([]parser.UserOption, len(options))
+		for i, option := range options {
+			option.DryRun = true
+			dryRun[i] = option
+		}
+		if resp, err = runEach(dryRun); err != nil {
+			return resp, fmt.Errorf("预览失败，未写入文件: %w", err)
+		}
+	}
+	return runEach(options)
+}
+
+func runEach(options []parser.UserOption) (resp []parser.FileChange, err error) {
+	resp = make([]parser.FileChange, 0)
+	var first *parser.Container
+	for _, option := range options {
+		parserObj := parser.NewParser(option)
+		if first == nil {
+			first = parserObj
+		}
+		parserObj.GenerateTime = first.GenerateTime
+		parserObj.GenerateTimeUnix = first.GenerateTimeUnix
+		err = parserObj.Run()
+		resp = append(resp, parserObj.GetChanges()...)
+		if err != nil {
+			if option.Binding != "" {
+				return resp, fmt.Errorf("模板%s: %w", option.Binding, err)
+			}
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// ProjectGen 使用项目绑定的所有模板生成代码，返回每个文件的生成结果，合并冲突的文件状态为conflict，删除的文件状态为removed
+func (p *projectSrv) ProjectGen(req InfoGen) (resp []parser.FileChange, err error) {
+	options, err := p.userOptions(InfoUniqId{Path: req.Path})
 	if err != nil {
-		return fmt.Errorf("生成代码失败: %w", err)
+		return nil, err
 	}
-	return
+	for i := range options {
+		options[i].Prune = req.Prune
+	}
+
+	resp, err = runOptions(options)
+	if err != nil {
+		return resp, fmt.Errorf("生成代码失败: %w", err)
+	}
+	return resp, nil
 }
 
-func (p *projectSrv) ProjectRender(req InfoUniqId) (resp parser.StoreData, err error) {
-	// 防止并发请求
-	info, err := p.ProjectInfo(req)
+// ProjectPreview 预览所有模板生成的代码，只在内存中渲染，返回每个文件的变更和diff
+func (p *projectSrv) ProjectPreview(req InfoUniqId) (resp []parser.FileChange, err error) {
+	options, err := p.userOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	for i := range options {
+		options[i].DryRun = true
+	}
+
+	resp, err = runOptions(options)
+	if err != nil {
+		return resp, fmt.Errorf("预览代码失败: %w", err)
+	}
+	return resp, nil
+}
+
+// ProjectUpgradePreview 在内存中分别用绑定模板当前的版本和候选版本生成代码，返回两次生成结果之间的差异，
+// 只比较模板渲染的内容，不受项目中手写代码的影响
+func (p *projectSrv) ProjectUpgradePreview(req InfoUpgradePreview) (resp UpgradePreview, err error) {
+	info, err := p.ProjectInfo(InfoUniqId{Path: req.Path})
 	if err != nil {
 		return resp, fmt.Errorf("获取projects失败: %w", err)
 	}
+	current, err := info.binding(req.PathKey)
+	if err != nil {
+		return resp, err
+	}
+	resp.PathKey = current.PathKey
+	resp.FromRef = current.TemplateRef
+	resp.ToRef = req.TemplateRef
 
-	templateInfo, err := template.Srv.TemplateInfo(template.InfoUniqId{GitRemotePath: template.GitURL(info.GitRemotePath)})
+	from, err := p.bindingOption(info, current)
 	if err != nil {
-		return resp, fmt.Errorf("获取模板信息失败: %w", err)
+		return resp, err
 	}
-	parserObj := parser.NewParser(parser.UserOption{
-		Mode:               "json",
-		Language:           info.Language,
-		ScaffoldDSLContent: info.DSL,
-		ProType:            info.ProType,
-		ApiPrefix:          info.ApiPrefix,
-		EnableModule:       make([]string, 0),
-		ProjectPath:        info.Path,
-		GitLocalPath:       templateInfo.Path,
-		EnableFormat:       false,
-		Path: map[string]string{
-			"backend": ".",
-		},
-	})
+	current.TemplateRef = req.TemplateRef
+	to, err := p.bindingOption(info, current)
+	if err != nil {
+		return resp, err
+	}
+	from.DryRun = true
+	to.DryRun = true
+
+	fromParser := parser.NewParser(from)
+	if err = fromParser.Run(); err != nil {
+		return resp, fmt.Errorf("使用当前模板版本生成代码失败: %w", err)
+	}
+	toParser := parser.NewParser(to)
+	// 两次生成使用相同的时间，避免模板中的generateTime产生差异
+	toParser.GenerateTime = fromParser.GenerateTime
+	toParser.GenerateTimeUnix = fromParser.GenerateTimeUnix
+	if err = toParser.Run(); err != nil {
+		return resp, fmt.Errorf("使用候选模板版本生成代码失败: %w", err)
+	}
+
+	resp.Files = parser.DiffGenerated(info.Path, fromParser.GetChanges(), toParser.GetChanges())
+	return resp, nil
+}
 
+func (p *projectSrv) ProjectRender(req InfoUniqId) (resp parser.StoreData, err error) {
+	option, err := p.userOption(req)
+	if err != nil {
+		return resp, err
+	}
+	option.Mode = "json"
+
+	parserObj := parser.NewParser(option)
 	err = parserObj.Run()
 	if err != nil {
 		return resp, fmt.Errorf("生成代码失败: %w", err)
@@ -368,6 +574,150 @@ func (p *projectSrv) ProjectRender(req InfoUniqId) (resp parser.StoreData, err e
 	return parserObj.GetRenderData(), nil
 }
 
+// ProjectVariables 获取绑定模板声明的变量和项目中已经填写的值
+func (p *projectSrv) ProjectVariables(req InfoBinding) (resp VariablesDto, err error) {
+	info, err := p.ProjectInfo(InfoUniqId{Path: req.Path})
+	if err != nil {
+		return resp, fmt.Errorf("获取projects失败: %w", err)
+	}
+	binding, err := info.binding(req.PathKey)
+	if err != nil {
+		return resp, err
+	}
+	option, err := binding.tmplOption()
+	if err != nil {
+		return resp, err
+	}
+	resp.Variables = option.Variables
+	if resp.Variables == nil {
+		resp.Variables = make([]parser.TmplVariable, 0)
+	}
+	resp.Values = binding.Variables
+	if resp.Values == nil {
+		resp.Values = make(map[string]interface{})
+	}
+	return resp, nil
+}
+
+// ProjectUpdateVariables 按绑定模板的声明校验并保存变量，只保存用户填写的值，没有填写的变量生成时使用模板的默认值
+func (p *projectSrv) ProjectUpdateVariables(req InfoVariables) (err error) {
+	info, err := p.ProjectInfo(InfoUniqId{Path: req.Path})
+	if err != nil {
+		return fmt.Errorf("获取projects失败: %w", err)
+	}
+	binding, err := info.binding(req.PathKey)
+	if err != nil {
+		return err
+	}
+	option, err := binding.tmplOption()
+	if err != nil {
+		return err
+	}
+	values, err := option.ValidateVariables(req.Variables)
+	if err != nil {
+		return fmt.Errorf("模板变量错误: %w", err)
+	}
+
+	// 防止并发请求
+	p.l.Lock()
+	defer p.l.Unlock()
+	value, err := p.leveldb.Get([]byte(constx.LevelDBProjects), nil)
+	if err != nil {
+		return fmt.Errorf("获取projects失败: %w", err)
+	}
+	projectsList := make([]Info, 0)
+	err = json.Unmarshal(value, &projectsList)
+	if err != nil {
+		return fmt.Errorf("解析项目json失败: %w", err)
+	}
+	for i := range projectsList {
+		if projectsList[i].Path == req.Path {
+			err = projectsList[i].updateBinding(binding.PathKey, func(b *TemplateBinding) {
+				b.Variables = values
+			})
+			if err != nil {
+				return err
+			}
+			projectsList[i].Utime = time.Now().Unix()
+		}
+	}
+	jsonBytes, err := json.Marshal(projectsList)
+	if err != nil {
+		return fmt.Errorf("JSON编码失败: %w", err)
+	}
+	err = p.leveldb.Put([]byte(constx.LevelDBProjects), jsonBytes, nil)
+	if err != nil {
+		return fmt.Errorf("写入leveldb失败: %w", err)
+	}
+	return nil
+}
+
+// ProjectUpdateTemplateRef 将项目绑定的模板切换到另一个版本，检出该版本并确认存在绑定的模板类型后保存
+func (p *projectSrv) ProjectUpdateTemplateRef(req InfoTemplateRef) (err error) {
+	info, err := p.ProjectInfo(InfoUniqId{Path: req.Path})
+	if err != nil {
+		return fmt.Errorf("获取projects失败: %w", err)
+	}
+	binding, err := info.binding(req.PathKey)
+	if err != nil {
+		return err
+	}
+	binding.TemplateRef = req.TemplateRef
+	if _, err = binding.tmplOption(); err != nil {
+		return fmt.Errorf("切换模板版本失败: %w", err)
+	}
+
+	// 防止并发请求
+	p.l.Lock()
+	defer p.l.Unlock()
+	value, err := p.leveldb.Get([]byte(constx.LevelDBProjects), nil)
+	if err != nil {
+		return fmt.Errorf("获取projects失败: %w", err)
+	}
+	projectsList := make([]Info, 0)
+	err = json.Unmarshal(value, &projectsList)
+	if err != nil {
+		return fmt.Errorf("解析项目json失败: %w", err)
+	}
+	for i := range projectsList {
+		if projectsList[i].Path == req.Path {
+			err = projectsList[i].updateBinding(binding.PathKey, func(b *TemplateBinding) {
+				b.TemplateRef = req.TemplateRef
+			})
+			if err != nil {
+				return err
+			}
+			projectsList[i].Utime = time.Now().Unix()
+		}
+	}
+	jsonBytes, err := json.Marshal(projectsList)
+	if err != nil {
+		return fmt.Errorf("JSON编码失败: %w", err)
+	}
+	err = p.leveldb.Put([]byte(constx.LevelDBProjects), jsonBytes, nil)
+	if err != nil {
+		return fmt.Errorf("写入leveldb失败: %w", err)
+	}
+	return nil
+}
+
+// ProjectExport 将项目DSL中的模型导出为JSON Schema或OpenAPI的components.schemas
+func (p *projectSrv) ProjectExport(req InfoExport) (resp interface{}, err error) {
+	info, err := p.ProjectInfo(InfoUniqId{Path: req.Path})
+	if err != nil {
+		return nil, fmt.Errorf("获取projects失败: %w", err)
+	}
+	models, err := parser.ParseDSL(info.DSL)
+	if err != nil {
+		return nil, fmt.Errorf("解析DSL失败: %w", err)
+	}
+	resp, err = exporter.Export(models, req.Format)
+	if err != nil {
+		return nil, fmt.Errorf("导出失败: %w", err)
+	}
+	return resp, nil
+}
+
 func (t *projectSrv) ProjectDelete(info InfoUniqId) (err error) {
 	// 防止并发请求
 	t.l.Lock()
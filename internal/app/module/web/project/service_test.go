@@ -0,0 +1,163 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/gotomicro/egoctl/internal/app/module/web/template"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+func TestRunOptionsFailure(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "project")
+	files := map[string]string{
+		"tmpl/ego-gin/egoctl.toml":       "renderPath = \"ego\"\n\n[[descriptor]]\nsrcName = \"model.go.tmpl\"\ndstPath = \"{$ pathBackend $}/model/{$ modelName $}.go\"\n",
+		"tmpl/ego-gin/ego/model.go.tmpl": "package model\n\n// {$ modelName $}\n",
+		// 第二个模板的when表达式错误
+		"tmpl/react/egoctl.toml":       "renderPath = \"web\"\n\n[[descriptor]]\nsrcName = \"page.tsx.tmpl\"\ndstPath = \"{$ pathWeb $}/{$ modelName $}.tsx\"\nwhen = \"language ==\"\n",
+		"tmpl/react/web/page.tsx.tmpl": "// {$ modelName $}\n",
+		"project/go.mod":               "module example.com/project\n\ngo 1.16\n",
+	}
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := map[string]string{PrimaryPathKey: ".", "web": "web"}
+	option := parser.UserOption{
+		Language:           "Go",
+		ScaffoldDSLContent: "package egoctl\n\ntype User struct {\n\tId int\n}\n",
+		ProType:            "ego-gin",
+		ProjectPath:        projectPath,
+		GitLocalPath:       filepath.Join(root, "tmpl"),
+		Path:               paths,
+	}
+	web := option
+	web.Language = "React"
+	web.ProType = "react"
+	web.Binding = "web"
+
+	changes, err := runOptions([]parser.UserOption{option, web})
+	if err == nil {
+		t.Fatal("want error when the second template fails")
+	}
+	// 返回出错前已经得到的生成结果，但所有模板预览成功之前不写入文件
+	if len(changes) != 1 || changes[0].Status != parser.FileStatusNew {
+		t.Fatalf("got changes %+v", changes)
+	}
+	if _, err = os.Stat(filepath.Join(projectPath, "model", "User.go")); !os.IsNotExist(err) {
+		t.Fatalf("want no file written, got %v", err)
+	}
+
+	changes, err = runOptions([]parser.UserOption{option})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("got changes %+v", changes)
+	}
+	if _, err = os.Stat(changes[0].Path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBindingVariables(t *testing.T) {
+	root := t.TempDir()
+	tmplPath := filepath.Join(root, "tmpl")
+	projectPath := filepath.Join(root, "project")
+	files := map[string]string{
+		"tmpl/ego-gin/egoctl.toml": "[[variables]]\nname = \"port\"\ntype = \"int\"\ndefault = 9001\n",
+		"tmpl/react/egoctl.toml":   "[[variables]]\nname = \"theme\"\ndefault = \"light\"\noptions = [\"light\", \"dark\"]\n",
+	}
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	templateSrv, projectSrv := template.Srv, Srv
+	defer func() { template.Srv, Srv = templateSrv, projectSrv }()
+	template.InitTemplateSrv(db)
+	InitProjectSrv(db)
+	templates, _ := json.Marshal([]template.Info{{Name: "tmpl", Kind: template.SourceDir, GitRemotePath: template.GitURL(tmplPath), Path: tmplPath}})
+	projects, _ := json.Marshal([]Info{{Path: projectPath, GitRemotePath: tmplPath, ProType: "ego-gin", Language: "Go", Variables: map[string]interface{}{"port": 8080}}})
+	if err = db.Put([]byte(constx.LevelDBTemplates), templates, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Put([]byte(constx.LevelDBProjects), projects, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	web := TemplateBinding{PathKey: "web", SubPath: "web", GitRemotePath: tmplPath, ProType: "react", Language: "React"}
+	// 绑定模板的变量按该模板的声明校验
+	web.Variables = map[string]interface{}{"theme": "blue"}
+	if err = Srv.ProjectUpdateTemplates(InfoTemplates{Path: projectPath, Templates: []TemplateBinding{web}}); err == nil {
+		t.Fatal("want error for a variable out of the options of the bound template")
+	}
+	web.Variables = map[string]interface{}{"theme": "dark"}
+	if err = Srv.ProjectUpdateTemplates(InfoTemplates{Path: projectPath, Templates: []TemplateBinding{web}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Srv.ProjectUpdateVariables(InfoVariables{Path: projectPath, PathKey: "web", Variables: map[string]interface{}{"theme": "port"}}); err == nil {
+		t.Fatal("want error for a variable out of the options of the bound template")
+	}
+	if err = Srv.ProjectUpdateVariables(InfoVariables{Path: projectPath, PathKey: "web", Variables: map[string]interface{}{"theme": "light"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = Srv.ProjectUpdateVariables(InfoVariables{Path: projectPath, PathKey: "admin"}); err == nil {
+		t.Fatal("want error for a template that is not bound")
+	}
+	resp, err := Srv.ProjectVariables(InfoBinding{Path: projectPath, PathKey: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Variables) != 1 || resp.Variables[0].Name != "theme" || resp.Values["theme"] != "light" {
+		t.Fatalf("got %+v", resp)
+	}
+	// 主模板的变量不受影响
+	resp, err = Srv.ProjectVariables(InfoBinding{Path: projectPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Variables) != 1 || resp.Variables[0].Name != "port" || fmt.Sprint(resp.Values["port"]) != "8080" || len(resp.Values) != 1 {
+		t.Fatalf("got %+v", resp)
+	}
+
+	// 本地目录模板不支持版本，切换绑定模板的版本失败时不修改项目
+	if err = Srv.ProjectUpdateTemplateRef(InfoTemplateRef{Path: projectPath, PathKey: "web", TemplateRef: "v1"}); err == nil {
+		t.Fatal("want error for a ref of a dir template")
+	}
+	if err = Srv.ProjectUpdateTemplateRef(InfoTemplateRef{Path: projectPath, PathKey: "web"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := Srv.ProjectInfo(InfoUniqId{Path: projectPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Templates) != 1 || info.Templates[0].Variables["theme"] != "light" || info.TemplateRef != "" {
+		t.Fatalf("got %+v", info)
+	}
+}
@@ -0,0 +1,419 @@
+package template
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
+	"github.com/gotomicro/egoctl/internal/git"
+	"github.com/gotomicro/egoctl/internal/system"
+	"github.com/gotomicro/egoctl/internal/utils"
+)
+
+const (
+	SourceGit     = "git"     // git仓库，同步时clone或pull
+	SourceDir     = "dir"     // 本地目录，直接使用，不需要同步
+	SourceArchive = "archive" // .tar.gz、.tgz或.zip压缩包，本地路径或http地址，同步时解压
+
+	archiveHashFile = ".egoctl-archive" // 解压目录中记录压缩包md5的文件
+)
+
+// SourceKind 模板来源，没有设置时根据地址判断
+func (info Info) SourceKind() string {
+	if info.Kind != "" {
+		return info.Kind
+	}
+	return sourceKind(string(info.GitRemotePath))
+}
+
+func sourceKind(location string) string {
+	switch {
+	case isArchive(location):
+		return SourceArchive
+	case utils.IsDir(location):
+		return SourceDir
+	}
+	return SourceGit
+}
+
+func isArchive(location string) bool {
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(strings.ToLower(location), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLocation 本地目录和本地压缩包使用绝对路径
+func normalizeLocation(kind string, location string) (string, error) {
+	if kind == SourceGit || strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return location, nil
+	}
+	abs, err := filepath.Abs(location)
+	if err != nil {
+		return "", fmt.Errorf("获取模板路径失败, err: %w", err)
+	}
+	return abs, nil
+}
+
+// localPath 根据模板来源获取存储路径，本地目录直接使用该目录
+func localPath(kind string, location string) (string, error) {
+	switch kind {
+	case SourceDir:
+		if !utils.IsDir(location) {
+			return "", fmt.Errorf("模板目录不存在: %s", location)
+		}
+		return location, nil
+	case SourceArchive:
+		if !isArchive(location) {
+			return "", fmt.Errorf("只支持.tar.gz、.tgz和.zip格式的压缩包")
+		}
+		return filepath.Join(system.EgoctlHome, "egoctl", "archive", fmt.Sprintf("%x", md5.Sum([]byte(location)))), nil
+	case SourceGit:
+		urlInfo, err := GitURL(location).Parse()
+		if err != nil {
+			return "", fmt.Errorf("URL解析失败, err: %w", err)
+		}
+		return system.EgoctlHome + "/egoctl/git" + urlInfo.Path, nil
+	}
+	return "", fmt.Errorf("不支持的模板来源%s", kind)
+}
+
+// Fetch 获取远程的更新并返回预览，不修改本地模板。git模板返回两个版本之间的提交和变化的文件
+func (info Info) Fetch() (SyncPreview, error) {
+	preview := SyncPreview{
+		Kind:  info.SourceKind(),
+		Logs:  make([]string, 0),
+		Files: make([]ChangeFile, 0),
+	}
+	switch preview.Kind {
+	case SourceDir:
+		// 本地目录直接使用，总是最新的
+		if !utils.IsDir(info.Path) {
+			return preview, fmt.Errorf("模板目录不存在: %s", info.Path)
+		}
+		preview.UpToDate = true
+		return preview, nil
+	case SourceArchive:
+		filename, hash, err := openArchive(string(info.GitRemotePath))
+		if err != nil {
+			return preview, err
+		}
+		if filename != string(info.GitRemotePath) {
+			os.Remove(filename)
+		}
+		local, _ := ioutil.ReadFile(filepath.Join(info.Path, archiveHashFile))
+		preview.LocalVersion = string(local)
+		preview.RemoteVersion = hash
+		preview.UpToDate = preview.LocalVersion == preview.RemoteVersion
+		return preview, nil
+	}
+
+	// 模板未下载，应用时clone
+	if !utils.IsDir(info.Path) {
+		return preview, nil
+	}
+	repo, err := git.OpenRepository(info.Path)
+	if err != nil {
+		return preview, fmt.Errorf("打开模板仓库失败, err: %w", err)
+	}
+	if err = repo.Fetch(); err != nil {
+		return preview, fmt.Errorf("获取远程模板失败, err: %w", err)
+	}
+	preview.LocalVersion = CurrentVersion(info.Path)
+	preview.RemoteVersion, err = repo.GetUpstreamVersion()
+	if err != nil {
+		return preview, fmt.Errorf("获取远程模板版本失败, err: %w", err)
+	}
+	preview.UpToDate = preview.LocalVersion == preview.RemoteVersion
+	if preview.UpToDate {
+		return preview, nil
+	}
+	logs, err := repo.GetChangeLogs(preview.LocalVersion, preview.RemoteVersion)
+	if err != nil {
+		return preview, fmt.Errorf("获取模板修改日志失败, err: %w", err)
+	}
+	for _, log := range logs {
+		if log != "" {
+			preview.Logs = append(preview.Logs, log)
+		}
+	}
+	files, err := repo.GetChangeFiles(preview.LocalVersion, preview.RemoteVersion, false)
+	if err != nil {
+		return preview, fmt.Errorf("获取模板变化的文件失败, err: %w", err)
+	}
+	for _, line := range files {
+		// M	ego-gin/egoctl.toml、R100	old	new
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		preview.Files = append(preview.Files, ChangeFile{Status: fields[0][:1], Path: fields[len(fields)-1]})
+	}
+	return preview, nil
+}
+
+// Apply 应用同步：git模板快进到version，version为空时先fetch再快进到远程的最新版本；
+// 压缩包重新解压，version不为空时压缩包的md5必须与预览时一致；本地目录只检查是否存在
+func (info Info) Apply(version string) error {
+	switch info.SourceKind() {
+	case SourceDir:
+		if !utils.IsDir(info.Path) {
+			return fmt.Errorf("模板目录不存在: %s", info.Path)
+		}
+		return nil
+	case SourceArchive:
+		return extractArchive(string(info.GitRemotePath), info.Path, version)
+	}
+
+	if !utils.IsDir(info.Path) {
+		return git.CloneRepo(string(info.GitRemotePath), info.Path)
+	}
+	repo, err := git.OpenRepository(info.Path)
+	if err != nil {
+		return fmt.Errorf("打开模板仓库失败, err: %w", err)
+	}
+	if version == "" {
+		if err = repo.Fetch(); err != nil {
+			return fmt.Errorf("获取远程模板失败, err: %w", err)
+		}
+		version, err = repo.GetUpstreamVersion()
+		if err != nil {
+			return fmt.Errorf("获取远程模板版本失败, err: %w", err)
+		}
+	}
+	if err = repo.FastForward(version); err != nil {
+		return fmt.Errorf("更新模板失败，本地模板可能被修改过, err: %w", err)
+	}
+	return nil
+}
+
+// RevisionPath 获取模板某个版本的本地路径，只有git模板支持版本，其他来源总是使用当前的内容
+func (info Info) RevisionPath(ref string) (string, error) {
+	if info.SourceKind() != SourceGit {
+		if ref != "" {
+			return "", fmt.Errorf("只有git模板支持版本，%s模板不能设置版本%s", info.SourceKind(), ref)
+		}
+		if !utils.IsDir(info.Path) {
+			return "", fmt.Errorf("模板未同步，请先同步模板")
+		}
+		return info.Path, nil
+	}
+	return RevisionPath(info.Path, ref)
+}
+
+// Version 模板当前的版本，git模板为commit，其他来源为空
+func (info Info) Version() string {
+	if info.SourceKind() != SourceGit {
+		return ""
+	}
+	return CurrentVersion(info.Path)
+}
+
+// openArchive 获取压缩包的本地文件和md5，http地址先下载到临时文件，由调用方删除
+func openArchive(location string) (filename string, hash string, err error) {
+	filename = location
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		filename, err = downloadArchive(location)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if filename != location {
+			os.Remove(filename)
+		}
+		return "", "", fmt.Errorf("读取压缩包失败, err: %w", err)
+	}
+	return filename, fmt.Sprintf("%x", md5.Sum(content)), nil
+}
+
+// extractArchive 将压缩包解压到dst，压缩包只有一个顶层目录并且该目录不是模板类型时，去掉该目录。
+// expectHash不为空时，压缩包的md5必须与其一致
+func extractArchive(location string, dst string, expectHash string) error {
+	filename, hash, err := openArchive(location)
+	if err != nil {
+		return err
+	}
+	if filename != location {
+		defer os.Remove(filename)
+	}
+	if expectHash != "" && expectHash != hash {
+		return fmt.Errorf("压缩包在预览后又有变化，请重新获取更新")
+	}
+
+	// 先解压到同一个目录下的临时目录，解压成功后再替换，避免解压失败时破坏原来的模板
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return fmt.Errorf("创建模板目录失败, err: %w", err)
+	}
+	tmpDir, err := ioutil.TempDir(filepath.Dir(dst), ".extract")
+	if err != nil {
+		return fmt.Errorf("创建解压目录失败, err: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if strings.HasSuffix(strings.ToLower(location), ".zip") {
+		err = extractZip(filename, tmpDir)
+	} else {
+		err = extractTarGz(filename, tmpDir)
+	}
+	if err != nil {
+		return fmt.Errorf("解压模板失败, err: %w", err)
+	}
+
+	root := tmpDir
+	files, err := ioutil.ReadDir(tmpDir)
+	if err != nil {
+		return fmt.Errorf("读取解压目录失败, err: %w", err)
+	}
+	if len(files) == 1 && files[0].IsDir() && !utils.IsExist(filepath.Join(tmpDir, files[0].Name(), parser.TmplConfigFile)) {
+		root = filepath.Join(tmpDir, files[0].Name())
+	}
+	err = ioutil.WriteFile(filepath.Join(root, archiveHashFile), []byte(hash), 0644)
+	if err != nil {
+		return fmt.Errorf("写入压缩包md5失败, err: %w", err)
+	}
+	if err = os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("删除原来的模板失败, err: %w", err)
+	}
+	if err = os.Rename(root, dst); err != nil {
+		return fmt.Errorf("移动模板失败, err: %w", err)
+	}
+	return nil
+}
+
+func downloadArchive(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("下载压缩包失败, err: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载压缩包失败, status: %s", resp.Status)
+	}
+	file, err := ioutil.TempFile("", "egoctl-template")
+	if err != nil {
+		return "", fmt.Errorf("创建临时文件失败, err: %w", err)
+	}
+	defer file.Close()
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("下载压缩包失败, err: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// archiveTarget 压缩包中文件的解压路径，拒绝解压到dst之外的路径
+func archiveTarget(dst string, name string) (string, error) {
+	target := filepath.Join(dst, filepath.FromSlash(name))
+	if target != dst && !strings.HasPrefix(target, dst+string(os.PathSeparator)) {
+		return "", fmt.Errorf("压缩包中的路径不合法: %s", name)
+	}
+	return target, nil
+}
+
+func extractTarGz(filename string, dst string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzReader.Close()
+	reader := tar.NewReader(gzReader)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveTarget(dst, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, os.ModePerm)
+		case tar.TypeReg:
+			err = writeArchiveFile(target, reader, os.FileMode(header.Mode))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(filename string, dst string) error {
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		target, err := archiveTarget(dst, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err = os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(target, rc, file.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeArchiveFile(target string, reader io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+// archiveStatus 压缩包模板的状态，本地压缩包的内容变化后提示重新同步
+func (info Info) archiveStatus() string {
+	hash, err := ioutil.ReadFile(filepath.Join(info.Path, archiveHashFile))
+	if err != nil {
+		return "模板未同步"
+	}
+	location := string(info.GitRemotePath)
+	if utils.IsExist(location) {
+		content, err := ioutil.ReadFile(location)
+		if err == nil && fmt.Sprintf("%x", md5.Sum(content)) != string(hash) {
+			return "压缩包已更新，请同步模板"
+		}
+	}
+	return "压缩包: " + string(hash)
+}
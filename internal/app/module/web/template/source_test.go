@@ -0,0 +1,77 @@
+package template
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractArchive(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"egoctl-tmpls-1.0/ego-gin/egoctl.toml":     "renderPath = \"ego\"\n",
+		"egoctl-tmpls-1.0/ego-gin/ego/main.go.tpl": "package main\n",
+	}
+
+	tgz := filepath.Join(dir, "tmpls.tar.gz")
+	file, err := os.Create(tgz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzWriter)
+	for name, content := range files {
+		err = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tarWriter.Close()
+	gzWriter.Close()
+	file.Close()
+
+	dst := filepath.Join(dir, "archive", "tmpls")
+	if err = extractArchive(tgz, dst, ""); err != nil {
+		t.Fatal(err)
+	}
+	// 顶层目录不是模板类型，解压时去掉
+	content, err := ioutil.ReadFile(filepath.Join(dst, "ego-gin", "ego", "main.go.tpl"))
+	if err != nil || string(content) != "package main\n" {
+		t.Fatalf("got %q, %v", content, err)
+	}
+	info := Info{GitRemotePath: GitURL(tgz), Path: dst}
+	if info.SourceKind() != SourceArchive {
+		t.Fatalf("got kind %s", info.SourceKind())
+	}
+	if got := info.StatusText(); !strings.HasPrefix(got, "压缩包: ") {
+		t.Fatalf("got status %s", got)
+	}
+
+	zipFile := filepath.Join(dir, "evil.zip")
+	file, err = os.Create(zipFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipWriter := zip.NewWriter(file)
+	writer, err := zipWriter.Create("../evil.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("evil"))
+	zipWriter.Close()
+	file.Close()
+	if err = extractArchive(zipFile, filepath.Join(dir, "archive", "evil"), ""); err == nil {
+		t.Fatal("want error for path outside the template directory")
+	}
+	if _, err = os.Stat(filepath.Join(dir, "archive", "evil.txt")); !os.IsNotExist(err) {
+		t.Fatal("file written outside the template directory")
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/url"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
 
 	"github.com/gotomicro/egoctl/internal/app/module/web/constx"
+	"github.com/gotomicro/egoctl/internal/app/module/web/parser"
 	"github.com/gotomicro/egoctl/internal/git"
 	"github.com/gotomicro/egoctl/internal/system"
 	"github.com/gotomicro/egoctl/internal/utils"
@@ -26,13 +29,15 @@ type templateSrv struct {
 
 type Info struct {
 	Name          string `json:"name" binding:"required"`          // 名称
-	GitRemotePath GitURL `json:"gitRemotePath" binding:"required"` // 远程地址
+	Kind          string `json:"kind"`                             // 模板来源：git、dir、archive，为空时根据地址判断
+	GitRemotePath GitURL `json:"gitRemotePath" binding:"required"` // 远程地址，本地目录和压缩包为路径
 	Path          string `json:"path"`                             // 存储路径
 }
 
 // 用户看到的列表数据
 type InfoDto struct {
 	Name          string `json:"name" binding:"required"`          // 名称
+	Kind          string `json:"kind"`                             // 模板来源
 	GitRemotePath GitURL `json:"gitRemotePath" binding:"required"` // 远程地址
 	Path          string `json:"path"`                             // 存储路径
 	StatusText    string `json:"statusText"`
@@ -45,6 +50,7 @@ func (i Infos) ToInfoDtos() []InfoDto {
 	for _, value := range i {
 		output = append(output, InfoDto{
 			Name:          value.Name,
+			Kind:          value.SourceKind(),
 			GitRemotePath: value.GitRemotePath,
 			Path:          value.Path,
 			StatusText:    value.StatusText(),
@@ -54,7 +60,41 @@ func (i Infos) ToInfoDtos() []InfoDto {
 }
 
 type InfoUniqId struct {
-	GitRemotePath GitURL `json:"gitRemotePath"  binding:"required"` // 远程地址
+	GitRemotePath GitURL `json:"gitRemotePath" form:"gitRemotePath" binding:"required"` // 远程地址
+}
+
+// 同步模板的预览
+type SyncPreview struct {
+	Kind          string       `json:"kind"`
+	LocalVersion  string       `json:"localVersion"`  // 本地的版本，git模板为commit，压缩包为md5，未下载时为空
+	RemoteVersion string       `json:"remoteVersion"` // 远程的版本，应用时更新到该版本
+	UpToDate      bool         `json:"upToDate"`      // 本地已经是最新的版本
+	Logs          []string     `json:"logs"`          // 两个版本之间的提交
+	Files         []ChangeFile `json:"files"`         // 两个版本之间变化的文件
+}
+
+// 变化的文件
+type ChangeFile struct {
+	Status string `json:"status"` // A新增、M修改、D删除、R重命名
+	Path   string `json:"path"`
+}
+
+// 应用同步参数
+type InfoApply struct {
+	GitRemotePath GitURL `json:"gitRemotePath" binding:"required"` // 远程地址
+	RemoteVersion string `json:"remoteVersion"`                    // 预览时的远程版本，为空时更新到最新版本
+}
+
+// 模板模块参数
+type InfoModules struct {
+	GitRemotePath GitURL `json:"gitRemotePath" form:"gitRemotePath" binding:"required"` // 远程地址
+	ProType       string `json:"proType" form:"proType"`                                // 模板类型，为空时返回所有模板类型
+}
+
+// 模板类型及其声明的模块
+type ProTypeModules struct {
+	ProType string              `json:"proType"`
+	Modules []parser.TmplModule `json:"modules"`
 }
 
 type GitURL string
@@ -97,8 +137,12 @@ type TmplURL struct {
 	Path string // 存储路径
 }
 
+// 检出固定版本模板的锁，防止并发检出同一个版本
+var revisionLock sync.Mutex
+
 var DefaultTemplateInfo = Info{
 	Name:          "EGO官方模板",
+	Kind:          SourceGit,
 	GitRemotePath: "https://github.com/gotomicro/egoctl-tmpls.git",
 	Path:          system.EgoctlHome + "/egoctl/git/gotomicro/egoctl-tmpls",
 }
@@ -140,11 +184,15 @@ func (t *templateSrv) TemplateList() ([]InfoDto, error) {
 }
 
 func (t *templateSrv) TemplateCreate(info Info) (err error) {
-	var urlInfo TmplURL
-	urlInfo, err = info.GitRemotePath.Parse()
+	info.Kind = info.SourceKind()
+	location, err := normalizeLocation(info.Kind, string(info.GitRemotePath))
 	if err != nil {
-		err = fmt.Errorf("URL解析失败, err: %w", err)
-		return
+		return err
+	}
+	info.GitRemotePath = GitURL(location)
+	info.Path, err = localPath(info.Kind, location)
+	if err != nil {
+		return err
 	}
 
 	// 防止并发请求
@@ -174,8 +222,9 @@ func (t *templateSrv) TemplateCreate(info Info) (err error) {
 	}
 	list = append(list, Info{
 		Name:          info.Name,
+		Kind:          info.Kind,
 		GitRemotePath: info.GitRemotePath,
-		Path:          system.EgoctlHome + "/egoctl/git" + urlInfo.Path,
+		Path:          info.Path,
 	})
 	jsonBytes, err := json.Marshal(list)
 	if err != nil {
@@ -290,19 +339,140 @@ func (t *templateSrv) TemplateDelete(info Info) (err error) {
 	return
 }
 
+// TemplateSync 获取并应用远程的最新版本，不预览
 func (t *templateSrv) TemplateSync(info InfoUniqId) (err error) {
 	tInfo, err := t.TemplateInfo(info)
 	if err != nil {
 		return err
 	}
-	err = git.CloneORPullRepo(string(tInfo.GitRemotePath), tInfo.Path)
+	return tInfo.Apply("")
+}
+
+// TemplateFetch 获取模板远程的更新，返回本地和远程版本之间的提交和变化的文件，不修改本地模板
+func (t *templateSrv) TemplateFetch(info InfoUniqId) (SyncPreview, error) {
+	tInfo, err := t.TemplateInfo(info)
+	if err != nil {
+		return SyncPreview{}, err
+	}
+	return tInfo.Fetch()
+}
+
+// TemplateApply 将模板更新到预览时的远程版本
+func (t *templateSrv) TemplateApply(info InfoApply) error {
+	tInfo, err := t.TemplateInfo(InfoUniqId{GitRemotePath: info.GitRemotePath})
 	if err != nil {
 		return err
 	}
-	return nil
+	return tInfo.Apply(info.RemoteVersion)
+}
+
+// TemplateTags 获取模板的tag列表，用于选择项目固定的模板版本
+func (t *templateSrv) TemplateTags(info InfoUniqId) ([]string, error) {
+	tInfo, err := t.TemplateInfo(info)
+	if err != nil {
+		return nil, err
+	}
+	if tInfo.SourceKind() != SourceGit {
+		return nil, fmt.Errorf("只有git模板支持版本")
+	}
+	if !utils.IsDir(tInfo.Path) {
+		return nil, fmt.Errorf("模板未下载，请先同步模板")
+	}
+	repo, err := git.OpenRepository(tInfo.Path)
+	if err != nil {
+		return nil, fmt.Errorf("打开模板仓库失败, err: %w", err)
+	}
+	tags, err := repo.GetTags()
+	if err != nil {
+		return nil, fmt.Errorf("获取模板tag失败, err: %w", err)
+	}
+	return tags, nil
+}
+
+// RevisionPath 获取模板某个版本的本地路径。ref为tag、分支或commit，为空时使用同步下来的最新代码；
+// 否则将该版本检出到 ~/.egoctl/egoctl/worktree/<commit>，同一个commit只检出一次
+func RevisionPath(localPath string, ref string) (string, error) {
+	if ref == "" {
+		return localPath, nil
+	}
+	repo, err := git.OpenRepository(localPath)
+	if err != nil {
+		return "", fmt.Errorf("模板未下载，请先同步模板: %w", err)
+	}
+	commit, err := repo.ResolveRef(ref)
+	if err != nil {
+		return "", fmt.Errorf("%w，请先同步模板", err)
+	}
+	dst := filepath.Join(system.EgoctlHome, "egoctl", "worktree", commit)
+
+	revisionLock.Lock()
+	defer revisionLock.Unlock()
+	if utils.IsDir(dst) {
+		return dst, nil
+	}
+	err = repo.AddWorktree(dst, commit)
+	if err != nil {
+		return "", fmt.Errorf("检出模板版本%s失败, err: %w", ref, err)
+	}
+	return dst, nil
+}
+
+// CurrentVersion 获取同步下来的模板的commit，模板未下载时返回空
+func CurrentVersion(localPath string) string {
+	repo, err := git.OpenRepository(localPath)
+	if err != nil {
+		return ""
+	}
+	version, err := repo.GetVersion()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(version)
+}
+
+// TemplateModules 获取模板中每个模板类型声明的模块
+func (t *templateSrv) TemplateModules(req InfoModules) ([]ProTypeModules, error) {
+	tInfo, err := t.TemplateInfo(InfoUniqId{GitRemotePath: req.GitRemotePath})
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IsDir(tInfo.Path) {
+		return nil, fmt.Errorf("模板未下载，请先同步模板")
+	}
+	proTypes := []string{req.ProType}
+	if req.ProType == "" {
+		proTypes = proTypes[:0]
+		dirs, err := ioutil.ReadDir(tInfo.Path)
+		if err != nil {
+			return nil, fmt.Errorf("读取模板目录失败, err: %w", err)
+		}
+		for _, dir := range dirs {
+			if dir.IsDir() && utils.IsExist(filepath.Join(tInfo.Path, dir.Name(), parser.TmplConfigFile)) {
+				proTypes = append(proTypes, dir.Name())
+			}
+		}
+	}
+	output := make([]ProTypeModules, 0, len(proTypes))
+	for _, proType := range proTypes {
+		option, err := parser.LoadTmplOption(tInfo.Path, proType)
+		if err != nil {
+			return nil, fmt.Errorf("读取模板类型%s失败: %w", proType, err)
+		}
+		output = append(output, ProTypeModules{ProType: proType, Modules: option.ModuleList()})
+	}
+	return output, nil
 }
 
 func (info Info) StatusText() (statusText string) {
+	switch info.SourceKind() {
+	case SourceDir:
+		if !utils.IsDir(info.Path) {
+			return "模板目录不存在"
+		}
+		return "本地目录"
+	case SourceArchive:
+		return info.archiveStatus()
+	}
 	if !utils.IsDir(info.Path) {
 		return "模板未下载"
 	}
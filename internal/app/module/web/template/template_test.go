@@ -0,0 +1,85 @@
+package template
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gotomicro/egoctl/internal/system"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=egoctl", "-c", "user.email=egoctl@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s, %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestRevisionPath(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	home := system.EgoctlHome
+	system.EgoctlHome = t.TempDir()
+	defer func() { system.EgoctlHome = home }()
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q")
+	for _, version := range []string{"v1", "v2"} {
+		if err := ioutil.WriteFile(filepath.Join(repo, "version"), []byte(version), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, repo, "add", "-A")
+		runGit(t, repo, "commit", "-q", "-m", version)
+		runGit(t, repo, "tag", version)
+	}
+	v1 := runGit(t, repo, "rev-parse", "v1")
+
+	// 不指定版本时使用同步下来的代码
+	got, err := RevisionPath(repo, "")
+	if err != nil || got != repo {
+		t.Fatalf("got %s %v, want %s", got, err, repo)
+	}
+
+	got, err = RevisionPath(repo, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(system.EgoctlHome, "egoctl", "worktree", v1); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(got, "version"))
+	if err != nil || string(content) != "v1" {
+		t.Fatalf("got worktree content %s %v, want v1", content, err)
+	}
+
+	// 同一个commit复用已检出的目录
+	marker := filepath.Join(got, "marker")
+	if err = ioutil.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	again, err := RevisionPath(repo, v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != got {
+		t.Fatalf("got %s, want reused %s", again, got)
+	}
+	if _, err = ioutil.ReadFile(marker); err != nil {
+		t.Fatalf("worktree was checked out again: %s", err)
+	}
+
+	if _, err = RevisionPath(repo, "v9"); err == nil || !strings.Contains(err.Error(), "v9") {
+		t.Fatalf("got error %v, want unknown ref error", err)
+	}
+	if _, err = RevisionPath(filepath.Join(repo, "missing"), "v1"); err == nil {
+		t.Fatal("want error when the template is not downloaded")
+	}
+}
@@ -0,0 +1,265 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OpKind 行级别的编辑类型
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// Op 一行的编辑操作，A、B分别为该操作在原内容和新内容中的行下标
+type Op struct {
+	Kind OpKind
+	A    int
+	B    int
+	Text string
+}
+
+// SplitLines 按行切分内容，去掉末尾换行产生的空行
+func SplitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	lines := strings.Split(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines 使用Myers算法计算两组行之间的最短编辑序列。采用线性空间的分治版本：
+// 每次找到编辑路径中间的重合点后分成两半递归，内存与行数成正比，与差异大小无关
+func Lines(a, b []string) []Op {
+	d := &differ{a: a, b: b, ops: make([]Op, 0, len(a)+len(b))}
+	d.compare(0, len(a), 0, len(b))
+	// 连续的修改中删除在前、插入在后，并重新计算每个操作的行下标
+	ops := d.ops
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].Kind != Equal {
+			j++
+		}
+		sort.SliceStable(ops[i:j], func(x, y int) bool {
+			return ops[i+x].Kind == Delete && ops[i+y].Kind == Insert
+		})
+		i = j
+	}
+	x, y := 0, 0
+	for i := range ops {
+		ops[i].A, ops[i].B = x, y
+		switch ops[i].Kind {
+		case Equal:
+			x++
+			y++
+		case Delete:
+			x++
+		case Insert:
+			y++
+		}
+	}
+	return ops
+}
+
+type differ struct {
+	a, b []string
+	ops  []Op
+}
+
+// compare 按顺序输出a[aLo:aHi]与b[bLo:bHi]之间的编辑操作
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// 相同的前缀和后缀直接输出，剩下的部分两边都不为空时编辑距离至少为2
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, Op{Kind: Equal, Text: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	aEnd, bEnd := aHi, bHi
+	for aLo < aEnd && bLo < bEnd && d.a[aEnd-1] == d.b[bEnd-1] {
+		aEnd--
+		bEnd--
+	}
+	switch {
+	case aLo == aEnd:
+		for _, text := range d.b[bLo:bEnd] {
+			d.ops = append(d.ops, Op{Kind: Insert, Text: text})
+		}
+	case bLo == bEnd:
+		for _, text := range d.a[aLo:aEnd] {
+			d.ops = append(d.ops, Op{Kind: Delete, Text: text})
+		}
+	default:
+		x, y, ok := d.bisect(aLo, aEnd, bLo, bEnd)
+		if ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aEnd, y, bEnd)
+		} else {
+			for _, text := range d.a[aLo:aEnd] {
+				d.ops = append(d.ops, Op{Kind: Delete, Text: text})
+			}
+			for _, text := range d.b[bLo:bEnd] {
+				d.ops = append(d.ops, Op{Kind: Insert, Text: text})
+			}
+		}
+	}
+	for _, text := range d.a[aEnd:aHi] {
+		d.ops = append(d.ops, Op{Kind: Equal, Text: text})
+	}
+}
+
+// bisect 从两端同时搜索最短编辑路径，返回两个方向的路径重合时正向路径的位置，
+// 该位置在某条最短路径上，可以作为分治的切分点；没有公共行时ok为false
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+	delta := n - m
+	// delta为奇数时在正向搜索中检查重合，否则在反向搜索中检查
+	front := delta%2 != 0
+	kStart, kEnd, rStart, rEnd := 0, 0, 0, 0
+	for step := 0; step < maxD; step++ {
+		for k := -step + kStart; k <= step-kEnd; k += 2 {
+			i := offset + k
+			var x1 int
+			if k == -step || (k != step && forward[i-1] < forward[i+1]) {
+				x1 = forward[i+1]
+			} else {
+				x1 = forward[i-1] + 1
+			}
+			y1 := x1 - k
+			for x1 < n && y1 < m && d.a[aLo+x1] == d.b[bLo+y1] {
+				x1++
+				y1++
+			}
+			forward[i] = x1
+			switch {
+			case x1 > n:
+				kEnd += 2
+			case y1 > m:
+				kStart += 2
+			case front:
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x1 >= n-backward[j] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+		for k := -step + rStart; k <= step-rEnd; k += 2 {
+			i := offset + k
+			var x2 int
+			if k == -step || (k != step && backward[i-1] < backward[i+1]) {
+				x2 = backward[i+1]
+			} else {
+				x2 = backward[i-1] + 1
+			}
+			y2 := x2 - k
+			for x2 < n && y2 < m && d.a[aHi-x2-1] == d.b[bHi-y2-1] {
+				x2++
+				y2++
+			}
+			backward[i] = x2
+			switch {
+			case x2 > n:
+				rEnd += 2
+			case y2 > m:
+				rStart += 2
+			case !front:
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 {
+					x1 := forward[j]
+					y1 := offset + x1 - j
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Unified 生成unified diff格式的文本，内容相同时返回空字符串
+func Unified(fromName, toName, from, to string, context int) string {
+	ops := Lines(SplitLines(from), SplitLines(to))
+
+	changes := make([]int, 0)
+	for i, op := range ops {
+		if op.Kind != Equal {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(changes); {
+		start := changes[i] - context
+		if start < 0 {
+			start = 0
+		}
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context {
+			j++
+		}
+		end := changes[j] + context + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		writeHunk(&sb, ops[start:end])
+		i = j + 1
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []Op) {
+	aCount, bCount := 0, 0
+	for _, op := range ops {
+		if op.Kind != Insert {
+			aCount++
+		}
+		if op.Kind != Delete {
+			bCount++
+		}
+	}
+	aStart, bStart := ops[0].A, ops[0].B
+	if aCount > 0 {
+		aStart++
+	}
+	if bCount > 0 {
+		bStart++
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+	for _, op := range ops {
+		switch op.Kind {
+		case Equal:
+			sb.WriteString(" ")
+		case Delete:
+			sb.WriteString("-")
+		case Insert:
+			sb.WriteString("+")
+		}
+		sb.WriteString(op.Text)
+		sb.WriteString("\n")
+	}
+}
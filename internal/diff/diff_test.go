@@ -0,0 +1,135 @@
+package diff
+
+import (
+	mathrand "math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	a := SplitLines("a\nb\nc\nd\n")
+	b := SplitLines("a\nc\nd\ne\n")
+	ops := Lines(a, b)
+
+	got := ""
+	for _, op := range ops {
+		switch op.Kind {
+		case Equal:
+			got += " " + op.Text
+		case Delete:
+			got += "-" + op.Text
+		case Insert:
+			got += "+" + op.Text
+		}
+	}
+	want := " a-b c d+e"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	got := Unified("a/user.go", "b/user.go", "package a\n\nfunc A() {}\n", "package a\n\nfunc B() {}\n", 3)
+	want := "--- a/user.go\n+++ b/user.go\n@@ -1,3 +1,3 @@\n package a\n \n-func A() {}\n+func B() {}\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = Unified("a/user.go", "b/user.go", "", "package a\n", 3)
+	want = `--- a/user.go
++++ b/user.go
+@@ -0,0 +1,1 @@
++package a
+`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Unified("a", "b", "same\n", "same\n", 3); got != "" {
+		t.Fatalf("got %q, want empty diff", got)
+	}
+}
+
+func TestLinesMinimal(t *testing.T) {
+	rand := mathrand.New(mathrand.NewSource(1))
+	for round := 0; round < 500; round++ {
+		a := make([]string, rand.Intn(30))
+		for i := range a {
+			a[i] = string(rune('a' + rand.Intn(4)))
+		}
+		b := make([]string, rand.Intn(30))
+		for i := range b {
+			b[i] = string(rune('a' + rand.Intn(4)))
+		}
+		ops := Lines(a, b)
+
+		// 按操作还原两边的内容，并检查行下标
+		gotA, gotB, edits := make([]string, 0), make([]string, 0), 0
+		for _, op := range ops {
+			if op.A != len(gotA) || op.B != len(gotB) {
+				t.Fatalf("a=%v b=%v: got op %+v at a %d b %d", a, b, op, len(gotA), len(gotB))
+			}
+			if op.Kind != Insert {
+				gotA = append(gotA, op.Text)
+			}
+			if op.Kind != Delete {
+				gotB = append(gotB, op.Text)
+			}
+			if op.Kind != Equal {
+				edits++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("a=%v b=%v: ops %+v do not rebuild the input", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); edits != want {
+			t.Fatalf("a=%v b=%v: got %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+// lcs 最长公共子序列的长度
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				dp[i][j] = dp[i+1][j+1] + 1
+			case dp[i+1][j] > dp[i][j+1]:
+				dp[i][j] = dp[i+1][j]
+			default:
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+	return dp[0][0]
+}
+
+func TestLinesRewritten(t *testing.T) {
+	// 整个文件重写时内存不能随差异大小平方增长
+	a := make([]string, 5000)
+	b := make([]string, 5000)
+	for i := range a {
+		a[i] = "old " + strconv.Itoa(i)
+		b[i] = "new " + strconv.Itoa(i)
+		if i%100 == 0 {
+			b[i] = a[i]
+		}
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := Lines(a, b)
+	runtime.ReadMemStats(&after)
+	if len(ops) != 10000-50 {
+		t.Fatalf("got %d ops", len(ops))
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Fatalf("allocated %d bytes", allocated)
+	}
+}
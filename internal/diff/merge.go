@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"strings"
+)
+
+const (
+	MarkerCurrent   = "<<<<<<< current"
+	MarkerSeparator = "======="
+	MarkerGenerated = ">>>>>>> generated"
+)
+
+// hunk 将base中[start, end)的行替换为lines
+type hunk struct {
+	start int
+	end   int
+	lines []string
+}
+
+func toHunks(ops []Op) []hunk {
+	hunks := make([]hunk, 0)
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+		h := hunk{start: ops[i].A, end: ops[i].A, lines: make([]string, 0)}
+		for ; i < len(ops) && ops[i].Kind != Equal; i++ {
+			if ops[i].Kind == Delete {
+				h.end++
+			} else {
+				h.lines = append(h.lines, ops[i].Text)
+			}
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
+
+func applyHunks(base []string, start, end int, hunks []hunk) []string {
+	output := make([]string, 0)
+	pos := start
+	for _, h := range hunks {
+		output = append(output, base[pos:h.start]...)
+		output = append(output, h.lines...)
+		pos = h.end
+	}
+	return append(output, base[pos:end]...)
+}
+
+// Merge3 以base为共同祖先，合并current和generated两份修改。
+// 两边修改了相同（或相邻）的行且内容不同时，写入冲突标记，返回冲突数量。
+func Merge3(base, current, generated string) (string, int) {
+	baseLines := SplitLines(base)
+	currentHunks := toHunks(Lines(baseLines, SplitLines(current)))
+	generatedHunks := toHunks(Lines(baseLines, SplitLines(generated)))
+
+	output := make([]string, 0)
+	conflicts := 0
+	pos, i, j := 0, 0, 0
+	for i < len(currentHunks) || j < len(generatedHunks) {
+		var start, end int
+		if j >= len(generatedHunks) || (i < len(currentHunks) && currentHunks[i].start <= generatedHunks[j].start) {
+			start, end = currentHunks[i].start, currentHunks[i].end
+		} else {
+			start, end = generatedHunks[j].start, generatedHunks[j].end
+		}
+
+		// 收集两边所有与该区域重叠或相邻的修改
+		currentGroup := make([]hunk, 0)
+		generatedGroup := make([]hunk, 0)
+		for {
+			if i < len(currentHunks) && currentHunks[i].start <= end {
+				if currentHunks[i].end > end {
+					end = currentHunks[i].end
+				}
+				currentGroup = append(currentGroup, currentHunks[i])
+				i++
+				continue
+			}
+			if j < len(generatedHunks) && generatedHunks[j].start <= end {
+				if generatedHunks[j].end > end {
+					end = generatedHunks[j].end
+				}
+				generatedGroup = append(generatedGroup, generatedHunks[j])
+				j++
+				continue
+			}
+			break
+		}
+
+		output = append(output, baseLines[pos:start]...)
+		currentLines := applyHunks(baseLines, start, end, currentGroup)
+		generatedLines := applyHunks(baseLines, start, end, generatedGroup)
+		switch {
+		case len(currentGroup) == 0:
+			output = append(output, generatedLines...)
+		case len(generatedGroup) == 0:
+			output = append(output, currentLines...)
+		case strings.Join(currentLines, "\n") == strings.Join(generatedLines, "\n"):
+			output = append(output, currentLines...)
+		default:
+			conflicts++
+			output = append(output, MarkerCurrent)
+			output = append(output, currentLines...)
+			output = append(output, MarkerSeparator)
+			output = append(output, generatedLines...)
+			output = append(output, MarkerGenerated)
+		}
+		pos = end
+	}
+	output = append(output, baseLines[pos:]...)
+	if len(output) == 0 {
+		return "", conflicts
+	}
+	return strings.Join(output, "\n") + "\n", conflicts
+}
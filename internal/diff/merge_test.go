@@ -0,0 +1,40 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "package a\n\nfunc A() {\n\treturn\n}\n\nfunc B() {}\n"
+	current := "package a\n\nfunc A() {\n\tprintln(\"hand written\")\n\treturn\n}\n\nfunc B() {}\n"
+	generated := "package a\n\nfunc A() {\n\treturn\n}\n\nfunc B() {}\n\nfunc C() {}\n"
+	want := "package a\n\nfunc A() {\n\tprintln(\"hand written\")\n\treturn\n}\n\nfunc B() {}\n\nfunc C() {}\n"
+
+	got, conflicts := Merge3(base, current, generated)
+	if conflicts != 0 {
+		t.Fatalf("got %d conflicts, want 0", conflicts)
+	}
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMerge3Conflict(t *testing.T) {
+	base := "a\nb\nc\n"
+	current := "a\nB1\nc\n"
+	generated := "a\nB2\nc\n"
+	want := "a\n" + MarkerCurrent + "\nB1\n" + MarkerSeparator + "\nB2\n" + MarkerGenerated + "\nc\n"
+
+	got, conflicts := Merge3(base, current, generated)
+	if conflicts != 1 {
+		t.Fatalf("got %d conflicts, want 1", conflicts)
+	}
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got, conflicts = Merge3(base, current, current)
+	if conflicts != 0 || got != current {
+		t.Fatalf("got %q with %d conflicts, want %q", got, conflicts, current)
+	}
+}
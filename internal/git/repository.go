@@ -131,6 +131,35 @@ func (repo *Repository) Pull() error {
 	return nil
 }
 
+// Fetch 拉取远程的提交和tag，不修改本地分支
+func (repo *Repository) Fetch() error {
+	logger.Log.Info("git fetch " + repo.Path)
+	_, stderr, err := command.ExecCmdDir(repo.Path, "git", "fetch", "--tags", "origin")
+	if err != nil {
+		return concatenateError(err, stderr)
+	}
+	return nil
+}
+
+// GetUpstreamVersion 获取当前分支跟踪的远程分支的commit
+func (repo *Repository) GetUpstreamVersion() (string, error) {
+	stdout, stderr, err := command.ExecCmdDir(repo.Path, "git", "rev-parse", "@{upstream}")
+	if err != nil {
+		return "", concatenateError(err, stderr)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// FastForward 将当前分支快进到commit，本地有远程没有的提交时返回错误
+func (repo *Repository) FastForward(commit string) error {
+	logger.Log.Info("git merge --ff-only " + commit + " " + repo.Path)
+	_, stderr, err := command.ExecCmdDir(repo.Path, "git", "merge", "--ff-only", commit)
+	if err != nil {
+		return concatenateError(err, stderr)
+	}
+	return nil
+}
+
 // GetTags 获取tag列表
 func (repo *Repository) GetTags() ([]string, error) {
 	stdout, stderr, err := command.ExecCmdDir(repo.Path, "git", "tag", "-l")
@@ -153,6 +182,32 @@ func (repo *Repository) GetVersion() (string, error) {
 	return stdout, nil
 }
 
+// ResolveRef 获取tag、分支或commit对应的commit，分支优先使用origin上的分支
+func (repo *Repository) ResolveRef(ref string) (string, error) {
+	for _, name := range []string{"origin/" + ref, ref} {
+		stdout, _, err := command.ExecCmdDir(repo.Path, "git", "rev-parse", "--verify", "--quiet", name+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(stdout), nil
+		}
+	}
+	return "", fmt.Errorf("找不到版本%s", ref)
+}
+
+// AddWorktree 在dst检出commit，dst不能已存在
+func (repo *Repository) AddWorktree(dst string, commit string) error {
+	// 清理目录已被删除的worktree记录，否则无法再次检出到相同的目录
+	_, stderr, err := command.ExecCmdDir(repo.Path, "git", "worktree", "prune")
+	if err != nil {
+		return concatenateError(err, stderr)
+	}
+	logger.Log.Info("git worktree add " + dst + " " + commit)
+	_, stderr, err = command.ExecCmdDir(repo.Path, "git", "worktree", "add", "--detach", dst, commit)
+	if err != nil {
+		return concatenateError(err, stderr)
+	}
+	return nil
+}
+
 // GetChangeLogs 获取两个版本之间的修改日志
 func (repo *Repository) GetChangeLogs(startVer, endVer string) ([]string, error) {
 	// git log --pretty=format:"%cd %cn: %s" --date=iso v1.8.0...v1.9.0
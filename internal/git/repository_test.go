@@ -0,0 +1,95 @@
+package git
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runGit 在dir中执行git命令，返回去掉空白的输出
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=egoctl", "-c", "user.email=egoctl@example.com", "-c", "init.defaultBranch=master"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s, %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commitFile 写入文件并提交，返回commit
+func commitFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", content)
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+func TestResolveRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	remote := t.TempDir()
+	runGit(t, remote, "init", "-q")
+	v1 := commitFile(t, remote, "version", "v1")
+	runGit(t, remote, "tag", "v1.0")
+	runGit(t, remote, "branch", "dev")
+
+	local := filepath.Join(t.TempDir(), "local")
+	runGit(t, remote, "clone", "-q", remote, local)
+	// 本地的dev分支停留在v1，远程的dev分支前进到v2
+	runGit(t, local, "branch", "dev", "origin/dev")
+	runGit(t, remote, "checkout", "-q", "dev")
+	v2 := commitFile(t, remote, "version", "v2")
+	runGit(t, local, "fetch", "-q", "origin")
+	// 只存在于本地的分支
+	runGit(t, local, "checkout", "-q", "-b", "feature")
+	feature := commitFile(t, local, "version", "feature")
+
+	repo, err := OpenRepository(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "v1.0", want: v1},
+		{ref: "dev", want: v2}, // 优先使用origin上的分支
+		{ref: "feature", want: feature},
+		{ref: v1, want: v1},
+		{ref: v2[:8], want: v2},
+	}
+	for _, c := range cases {
+		got, err := repo.ResolveRef(c.ref)
+		if err != nil {
+			t.Fatalf("ref %s: %s", c.ref, err)
+		}
+		if got != c.want {
+			t.Fatalf("ref %s: got %s, want %s", c.ref, got, c.want)
+		}
+	}
+
+	if _, err = repo.ResolveRef("v9.9"); err == nil || !strings.Contains(err.Error(), "v9.9") {
+		t.Fatalf("got error %v, want unknown ref error", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "worktree")
+	if err = repo.AddWorktree(dst, v1); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dst, "version"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "v1" {
+		t.Fatalf("got worktree content %s, want v1", content)
+	}
+}
@@ -19,8 +19,11 @@ import (
 	"os"
 
 	"github.com/gotomicro/egoctl/cmd"
+	_ "github.com/gotomicro/egoctl/cmd/dsl"
+	_ "github.com/gotomicro/egoctl/cmd/gen"
 	_ "github.com/gotomicro/egoctl/cmd/migrate"
 	_ "github.com/gotomicro/egoctl/cmd/run"
+	_ "github.com/gotomicro/egoctl/cmd/template"
 	_ "github.com/gotomicro/egoctl/cmd/version"
 	_ "github.com/gotomicro/egoctl/cmd/web"
 	"github.com/gotomicro/egoctl/internal/config"
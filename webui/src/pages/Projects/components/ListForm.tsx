@@ -19,6 +19,7 @@ const ListForm: React.FC<ListFormProps> = (props) => {
   const { modalVisible, onCancel, onSubmit, initialValues, formTitle } = props;
   const [form] = Form.useForm();
   const [selectData, setSelectData] = useState([]); // 设置select
+  const [moduleData, setModuleData] = useState([]); // 模板类型声明的模块
 
   useEffect(() => {
     if (initialValues) {
@@ -47,6 +48,29 @@ const ListForm: React.FC<ListFormProps> = (props) => {
     })
   }, []);
 
+  // 根据模板和模板类型加载可选的模块
+  const loadModules = () => {
+    const gitRemotePath = form.getFieldValue("gitRemotePath");
+    const proType = form.getFieldValue("proType");
+    if (!gitRemotePath || !proType) {
+      setModuleData([]);
+      return;
+    }
+    api.TemplateModules({gitRemotePath, proType}).then((r)=>{
+      if (r.code !== 0) {
+        setModuleData([]);
+        return;
+      }
+      setModuleData(((r.data || [])[0] || {}).modules || []);
+    })
+  };
+
+  useEffect(() => {
+    if (modalVisible) {
+      loadModules();
+    }
+  }, [modalVisible, initialValues]);
+
   const laguageSelect = [{
     title: "Go",
     value: "Go",
@@ -97,6 +121,7 @@ const ListForm: React.FC<ListFormProps> = (props) => {
             style={{ width: '100%' }}
             placeholder="模板"
             optionFilterProp={"name"}
+            onChange={loadModules}
           >
             {  (selectData || []).map((item,index)=>{
               return (<Select.Option key={index} name={item.title} value={item.value}>{item.title}</Select.Option>)
@@ -121,7 +146,24 @@ const ListForm: React.FC<ListFormProps> = (props) => {
           name="proType"
           label="模板类型"
         >
-          <Input />
+          <Input onBlur={loadModules} />
+        </Form.Item>
+        <Form.Item
+          name="enableModule"
+          label="开启模块"
+          extra="不选择时生成所有模块，依赖的模块会一起生成"
+          normalize={(value) => (value && value.length > 0 ? value : undefined)}
+        >
+          <Select
+            mode="multiple"
+            style={{ width: '100%' }}
+            placeholder="所有模块"
+            optionFilterProp={"name"}
+          >
+            {  (moduleData || []).map((item: any)=>{
+              return (<Select.Option key={item.name} name={item.name} value={item.name}>{item.description ? `${item.name}（${item.description}）` : item.name}</Select.Option>)
+            })}
+          </Select>
         </Form.Item>
         <Form.Item
           name="apiPrefix"
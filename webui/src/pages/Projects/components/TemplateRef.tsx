@@ -0,0 +1,133 @@
+import { AutoComplete, Button, Collapse, Form, Modal, Tag, notification } from "antd";
+import React, { useEffect, useState } from "react";
+import api from "@/services/api";
+
+interface TemplateRefProps {
+  modalVisible: boolean;
+  formTitle: string;
+  initialValues: any;
+  onSubmit: (value: any) => void;
+  onCancel: () => void;
+}
+
+const statusColor = {
+  new: "green",
+  changed: "blue",
+  removed: "red",
+};
+
+const formLayout = {
+  labelCol: { span: 7 },
+  wrapperCol: { span: 13 },
+};
+
+// 切换项目使用的模板版本，可以选择tag，也可以填写分支或commit
+const TemplateRef: React.FC<TemplateRefProps> = (props) => {
+  const { modalVisible, onCancel, onSubmit, initialValues, formTitle } = props;
+  const [form] = Form.useForm();
+  const [tags, setTags] = useState([]);
+  const [upgrade, setUpgrade] = useState<any>(null);
+  const [loading, setLoading] = useState(false);
+
+  useEffect(() => {
+    if (!modalVisible || !initialValues || !initialValues.gitRemotePath) {
+      return;
+    }
+    form.resetFields();
+    setUpgrade(null);
+    form.setFieldsValue({ templateRef: initialValues.templateRef });
+    api.TemplateTags({ gitRemotePath: initialValues.gitRemotePath }).then((r) => {
+      if (r.code !== 0) {
+        notification.error({
+          message: "加载模板tag失败：" + r.msg,
+        });
+        setTags([]);
+        return;
+      }
+      setTags(r.data || []);
+    });
+  }, [modalVisible, initialValues]);
+
+  // 在内存中用当前版本和候选版本分别生成代码，查看切换后有多少文件会变化
+  const handleUpgradePreview = () => {
+    setLoading(true);
+    api
+      .ProjectUpgradePreview({ path: initialValues.path, templateRef: form.getFieldValue("templateRef") || "" })
+      .then((r) => {
+        setLoading(false);
+        if (r.code !== 0) {
+          notification.error({
+            message: "预览模板升级失败：" + r.msg,
+          });
+          setUpgrade(null);
+          return;
+        }
+        setUpgrade(r.data);
+      });
+  };
+
+  const handleSubmit = () => {
+    if (!form) return;
+    form.submit();
+  };
+
+  const modalFooter = { okText: "切换", onOk: handleSubmit, onCancel };
+
+  return (
+    <Modal
+      width={1000}
+      destroyOnClose
+      title={formTitle}
+      visible={modalVisible}
+      {...modalFooter}
+    >
+      <Form
+        {...formLayout}
+        form={form}
+        onFinish={(values) => onSubmit({ path: initialValues.path, templateRef: values.templateRef || "" })}
+        scrollToFirstError
+      >
+        <Form.Item
+          name="templateRef"
+          label="模板版本"
+          extra="tag、分支或commit，为空时使用同步下来的最新模板"
+        >
+          <AutoComplete
+            style={{ width: "100%" }}
+            placeholder="最新模板"
+            options={tags.map((tag) => ({ value: tag }))}
+            filterOption={(input, option) => String(option?.value).indexOf(input) !== -1}
+          />
+        </Form.Item>
+        <Form.Item wrapperCol={{ offset: 7, span: 13 }}>
+          <Button loading={loading} onClick={handleUpgradePreview}>
+            预览影响
+          </Button>
+        </Form.Item>
+      </Form>
+      {upgrade && (
+        <>
+          <p>
+            切换到{upgrade.toRef || "最新模板"}后，共有{(upgrade.files || []).length}个生成的文件会变化
+          </p>
+          <Collapse>
+            {(upgrade.files || []).map((item: any) => (
+              <Collapse.Panel
+                key={item.path}
+                header={
+                  <>
+                    <Tag color={statusColor[item.status]}>{item.status}</Tag>
+                    {item.path}
+                  </>
+                }
+              >
+                <pre style={{ maxHeight: 400, overflow: "auto" }}>{item.diff}</pre>
+              </Collapse.Panel>
+            ))}
+          </Collapse>
+        </>
+      )}
+    </Modal>
+  );
+};
+export default TemplateRef;
@@ -0,0 +1,142 @@
+import { Button, Card, Form, Input, Modal, notification, Select } from "antd";
+import React, { useEffect, useState } from "react";
+import api from "@/services/api";
+
+interface TemplatesProps {
+  modalVisible: boolean;
+  formTitle: string;
+  initialValues: any;
+  onSubmit: (value: any) => void;
+  onCancel: () => void;
+}
+
+const formLayout = {
+  labelCol: { span: 7 },
+  wrapperCol: { span: 13 },
+};
+
+const languageSelect = ["Go", "React", "Vue", "其他"];
+
+// 项目在主模板之外绑定的模板，例如前端模板，生成代码时使用同一份DSL生成到各自的目录
+const Templates: React.FC<TemplatesProps> = (props) => {
+  const { modalVisible, onCancel, onSubmit, initialValues, formTitle } = props;
+  const [form] = Form.useForm();
+  const [selectData, setSelectData] = useState([]);
+
+  useEffect(() => {
+    if (!modalVisible || !initialValues) {
+      return;
+    }
+    form.resetFields();
+    form.setFieldsValue({ templates: initialValues.templates || [] });
+    api.TemplateSelect().then((r) => {
+      if (r.code !== 0) {
+        notification.error({
+          message: "加载失败",
+        });
+        return;
+      }
+      setSelectData(r.data || []);
+    });
+  }, [modalVisible, initialValues]);
+
+  const handleSubmit = () => {
+    if (!form) return;
+    form.submit();
+  };
+
+  const modalFooter = { okText: "保存", onOk: handleSubmit, onCancel };
+
+  return (
+    <Modal
+      width={1000}
+      destroyOnClose
+      title={formTitle}
+      visible={modalVisible}
+      {...modalFooter}
+    >
+      <p>主模板生成到项目目录，模板中通过pathBackend引用；绑定的模板生成到输出目录，例如pathKey为frontend时通过pathFrontend引用</p>
+      <Form
+        {...formLayout}
+        form={form}
+        onFinish={(values) => onSubmit({ path: initialValues.path, templates: values.templates || [] })}
+        scrollToFirstError
+      >
+        <Form.List name="templates">
+          {(fields, { add, remove }) => (
+            <>
+              {fields.map((field) => (
+                <Card
+                  key={field.key}
+                  size="small"
+                  style={{ marginBottom: 16 }}
+                  extra={<a onClick={() => remove(field.name)}>删除</a>}
+                >
+                  <Form.Item
+                    name={[field.name, "pathKey"]}
+                    label="pathKey"
+                    rules={[{ required: true, message: "请填写pathKey" }]}
+                  >
+                    <Input placeholder="frontend" />
+                  </Form.Item>
+                  <Form.Item
+                    name={[field.name, "subPath"]}
+                    label="输出目录"
+                    extra="相对项目目录的路径"
+                    rules={[{ required: true, message: "请填写输出目录" }]}
+                  >
+                    <Input placeholder="web" />
+                  </Form.Item>
+                  <Form.Item
+                    name={[field.name, "gitRemotePath"]}
+                    label="模板"
+                    rules={[{ required: true, message: "请选择模板" }]}
+                  >
+                    <Select style={{ width: "100%" }} placeholder="模板" optionFilterProp={"name"}>
+                      {(selectData || []).map((item: any, index) => {
+                        return (
+                          <Select.Option key={index} name={item.title} value={item.value}>
+                            {item.title}
+                          </Select.Option>
+                        );
+                      })}
+                    </Select>
+                  </Form.Item>
+                  <Form.Item name={[field.name, "language"]} label="语言">
+                    <Select style={{ width: "100%" }} placeholder="语言">
+                      {languageSelect.map((item) => {
+                        return (
+                          <Select.Option key={item} value={item}>
+                            {item}
+                          </Select.Option>
+                        );
+                      })}
+                    </Select>
+                  </Form.Item>
+                  <Form.Item
+                    name={[field.name, "proType"]}
+                    label="模板类型"
+                    rules={[{ required: true, message: "请填写模板类型" }]}
+                  >
+                    <Input />
+                  </Form.Item>
+                  <Form.Item
+                    name={[field.name, "templateRef"]}
+                    label="模板版本"
+                    extra="tag、分支或commit，为空时固定为模板当前的版本"
+                  >
+                    <Input />
+                  </Form.Item>
+                </Card>
+              ))}
+              <Button type="dashed" block onClick={() => add({ subPath: "web", language: "React" })}>
+                添加模板
+              </Button>
+            </>
+          )}
+        </Form.List>
+      </Form>
+    </Modal>
+  );
+};
+export default Templates;
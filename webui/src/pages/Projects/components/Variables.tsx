@@ -0,0 +1,105 @@
+import { Form, Input, InputNumber, Modal, notification, Select, Switch } from "antd";
+import React, { useEffect, useState } from "react";
+import api from "@/services/api";
+
+interface VariablesProps {
+  modalVisible: boolean;
+  formTitle: string;
+  initialValues: any;
+  onSubmit: (value: any) => void;
+  onCancel: () => void;
+}
+
+const formLayout = {
+  labelCol: { span: 7 },
+  wrapperCol: { span: 13 },
+};
+
+// 根据模板在egoctl.toml中声明的[[variables]]生成表单
+const Variables: React.FC<VariablesProps> = (props) => {
+  const { modalVisible, onCancel, onSubmit, initialValues, formTitle } = props;
+  const [form] = Form.useForm();
+  const [variables, setVariables] = useState([]);
+
+  useEffect(() => {
+    if (!modalVisible || !initialValues || !initialValues.path) {
+      return;
+    }
+    api.ProjectVariables({ path: initialValues.path }).then((r) => {
+      if (r.code !== 0) {
+        notification.error({
+          message: "加载模板变量失败：" + r.msg,
+        });
+        return;
+      }
+      const values = {};
+      (r.data.variables || []).forEach((item) => {
+        const value = r.data.values[item.name];
+        values[item.name] = value === undefined ? item.default : value;
+      });
+      setVariables(r.data.variables || []);
+      form.resetFields();
+      form.setFieldsValue(values);
+    });
+  }, [modalVisible, initialValues]);
+
+  const handleSubmit = () => {
+    if (!form) return;
+    form.submit();
+  };
+
+  const renderInput = (item) => {
+    if (item.options && item.options.length > 0) {
+      return (
+        <Select style={{ width: "100%" }}>
+          {item.options.map((option) => {
+            return (<Select.Option key={String(option)} value={option}>{String(option)}</Select.Option>);
+          })}
+        </Select>
+      );
+    }
+    switch (item.type) {
+      case "int":
+        return <InputNumber precision={0} style={{ width: "100%" }} />;
+      case "bool":
+        return <Switch />;
+      default:
+        return <Input />;
+    }
+  };
+
+  const modalFooter = { okText: "保存", onOk: handleSubmit, onCancel };
+
+  return (
+    <Modal
+      width={1000}
+      destroyOnClose
+      title={formTitle}
+      visible={modalVisible}
+      {...modalFooter}
+    >
+      <Form
+        {...formLayout}
+        form={form}
+        onFinish={(values) => onSubmit({ path: initialValues.path, variables: values })}
+        scrollToFirstError
+      >
+        {variables.length === 0 && <p>该模板没有声明变量</p>}
+        {variables.map((item) => {
+          return (
+            <Form.Item
+              key={item.name}
+              name={item.name}
+              label={item.name}
+              extra={item.help}
+              valuePropName={item.type === "bool" ? "checked" : "value"}
+            >
+              {renderInput(item)}
+            </Form.Item>
+          );
+        })}
+      </Form>
+    </Modal>
+  );
+};
+export default Variables;
@@ -4,6 +4,9 @@ import React, {Fragment, useRef, useState} from 'react';
 import ListForm from "./components/ListForm"
 import Editor from "./components/Editor"
 import Render from "./components/Render"
+import Variables from "./components/Variables"
+import TemplateRef from "./components/TemplateRef"
+import Templates from "./components/Templates"
 import {PlusOutlined} from '@ant-design/icons';
 import SearchTable, {SearchTableInstance} from '@/components/SearchTable';
 import api from "@/services/api";
@@ -53,6 +56,11 @@ const handleDSL = async (values) => {
     const resp = await api.ProjectUpdateDSL(values)
     if (resp.code !== 0) {
       hide();
+      if (Array.isArray(resp.data)) {
+        // DSL诊断信息: [{line, column, message}]
+        message.error('DSL存在错误：' + resp.data.map((d) => `第${d.line}行第${d.column}列 ${d.message}`).join('；'));
+        return false
+      }
       message.error('更新DSL失败，错误信息：' + resp.msg);
       return true
     }
@@ -66,11 +74,71 @@ const handleDSL = async (values) => {
   }
 };
 
+const handleVariables = async (values) => {
+  const hide = message.loading('正在保存模板变量');
+  try {
+    const resp = await api.ProjectUpdateVariables(values)
+    if (resp.code !== 0) {
+      hide();
+      message.error('保存失败，错误信息：' + resp.msg);
+      return false
+    }
+    hide();
+    message.success('保存成功');
+    return true;
+  } catch (error) {
+    hide();
+    message.error('保存失败请重试！' + error);
+    return false;
+  }
+};
+
+const handleTemplateRef = async (values) => {
+  const hide = message.loading('正在切换模板版本');
+  try {
+    const resp = await api.ProjectUpdateTemplateRef(values)
+    if (resp.code !== 0) {
+      hide();
+      message.error('切换失败，错误信息：' + resp.msg);
+      return false
+    }
+    hide();
+    message.success('切换成功，重新生成代码后生效');
+    return true;
+  } catch (error) {
+    hide();
+    message.error('切换失败请重试！' + error);
+    return false;
+  }
+};
+
+const handleTemplates = async (values) => {
+  const hide = message.loading('正在保存绑定的模板');
+  try {
+    const resp = await api.ProjectUpdateTemplates(values)
+    if (resp.code !== 0) {
+      hide();
+      message.error('保存失败，错误信息：' + resp.msg);
+      return false
+    }
+    hide();
+    message.success('保存成功，重新生成代码后生效');
+    return true;
+  } catch (error) {
+    hide();
+    message.error('保存失败请重试！' + error);
+    return false;
+  }
+};
+
 const TableList: React.FC<{}> = () => {
   const [createModalVisible, handleCreateModalVisible] = useState<boolean>(false);
   const [updateModalVisible, handleUpdateModalVisible] = useState<boolean>(false);
   const [editorModalVisible, handleEditorModalVisible] = useState<boolean>(false);
   const [renderModalVisible, handleRenderModalVisible] = useState<boolean>(false);
+  const [variablesModalVisible, handleVariablesModalVisible] = useState<boolean>(false);
+  const [templateRefModalVisible, handleTemplateRefModalVisible] = useState<boolean>(false);
+  const [templatesModalVisible, handleTemplatesModalVisible] = useState<boolean>(false);
   const [initialValues, setInitialValues] = useState({});
   const [form] = Form.useForm();
   const actionRef = useRef<SearchTableInstance>();
@@ -87,6 +155,14 @@ const TableList: React.FC<{}> = () => {
       title: "模板名称",
       dataIndex: "templateName",
       key: "templateName",
+    }, {
+      title: "模板版本",
+      dataIndex: "templateRef",
+      key: "templateRef",
+      render(val) {
+        // commit只展示前8位
+        return val ? (/^[0-9a-f]{40}$/.test(val) ? val.substring(0, 8) : val) : "最新"
+      },
     }, {
       title: "语言",
       dataIndex: "language",
@@ -126,6 +202,33 @@ const TableList: React.FC<{}> = () => {
             DSL描述
           </a>
           <Divider type="vertical"/>
+          <a
+            onClick={() => {
+              setInitialValues(record);
+              handleVariablesModalVisible(true);
+            }}
+          >
+            模板变量
+          </a>
+          <Divider type="vertical"/>
+          <a
+            onClick={() => {
+              setInitialValues(record);
+              handleTemplateRefModalVisible(true);
+            }}
+          >
+            模板版本
+          </a>
+          <Divider type="vertical"/>
+          <a
+            onClick={() => {
+              setInitialValues(record);
+              handleTemplatesModalVisible(true);
+            }}
+          >
+            绑定模板
+          </a>
+          <Divider type="vertical"/>
           <a
             onClick={() => {
               api.ProjectGen(record).then((res) => {
@@ -266,6 +369,56 @@ const TableList: React.FC<{}> = () => {
         modalVisible={editorModalVisible}
         initialValues={initialValues}
       />
+      <Variables
+        formTitle={"模板变量"}
+        onSubmit={async (value) => {
+          const success = await handleVariables(value);
+          if (success) {
+            setInitialValues({});
+            handleVariablesModalVisible(false);
+          }
+        }}
+        onCancel={() => {
+          setInitialValues({})
+          handleVariablesModalVisible(false)
+        }}
+        modalVisible={variablesModalVisible}
+        initialValues={initialValues}
+      />
+      <TemplateRef
+        formTitle={"模板版本"}
+        onSubmit={async (value) => {
+          const success = await handleTemplateRef(value);
+          if (success) {
+            setInitialValues({});
+            handleTemplateRefModalVisible(false);
+            actionRef.current?.refresh();
+          }
+        }}
+        onCancel={() => {
+          setInitialValues({})
+          handleTemplateRefModalVisible(false)
+        }}
+        modalVisible={templateRefModalVisible}
+        initialValues={initialValues}
+      />
+      <Templates
+        formTitle={"绑定模板"}
+        onSubmit={async (value) => {
+          const success = await handleTemplates(value);
+          if (success) {
+            setInitialValues({});
+            handleTemplatesModalVisible(false);
+            actionRef.current?.refresh();
+          }
+        }}
+        onCancel={() => {
+          setInitialValues({})
+          handleTemplatesModalVisible(false)
+        }}
+        modalVisible={templatesModalVisible}
+        initialValues={initialValues}
+      />
       <Render
         formTitle={"展示渲染数据"}
         onCancel={() => {
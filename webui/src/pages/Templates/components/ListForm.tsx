@@ -1,4 +1,4 @@
-import {Form, Input, Modal} from 'antd';
+import {Form, Input, Modal, Select} from 'antd';
 import React, {useEffect} from "react";
 
 interface ListFormProps {
@@ -54,11 +54,23 @@ const ListForm: React.FC<ListFormProps> = (props) => {
         >
           <Input/>
         </Form.Item>
+        <Form.Item
+          name="kind"
+          label="模板来源"
+          extra="不选择时根据地址判断"
+        >
+          <Select allowClear disabled={initialValues.mode !== "create"} placeholder="自动判断">
+            <Select.Option value="git">git仓库</Select.Option>
+            <Select.Option value="dir">本地目录，直接使用</Select.Option>
+            <Select.Option value="archive">.tar.gz、.zip压缩包</Select.Option>
+          </Select>
+        </Form.Item>
         <Form.Item
           name="gitRemotePath"
           label="项目路径"
+          extra="git地址、本地目录，或压缩包的本地路径、http地址"
         >
-          <Input/>
+          <Input disabled={initialValues.mode !== "create"}/>
         </Form.Item>
         {initialValues.mode !== "create" && <Form.Item
           name="path"
@@ -0,0 +1,64 @@
+import { List, Modal, Tag } from "antd";
+import React from "react";
+
+interface SyncPreviewProps {
+  modalVisible: boolean;
+  preview: any;
+  onSubmit: () => void;
+  onCancel: () => void;
+}
+
+const statusColor = {
+  A: "green",
+  M: "blue",
+  D: "red",
+  R: "orange",
+};
+
+// 同步模板前预览本地和远程版本之间的提交和变化的文件
+const SyncPreview: React.FC<SyncPreviewProps> = (props) => {
+  const { modalVisible, onCancel, onSubmit, preview } = props;
+  const short = (version, empty) => (version && version.length === 40 ? version.substring(0, 8) : version) || empty;
+
+  return (
+    <Modal
+      width={1000}
+      destroyOnClose
+      title={"同步模板"}
+      visible={modalVisible}
+      okText={preview.upToDate ? "关闭" : "更新"}
+      onOk={preview.upToDate ? onCancel : onSubmit}
+      onCancel={onCancel}
+    >
+      <p>
+        本地版本：{short(preview.localVersion, "未下载")}，远程版本：{short(preview.remoteVersion, "最新")}
+        {preview.upToDate && "，已经是最新版本"}
+      </p>
+      {(preview.logs || []).length > 0 && (
+        <List
+          header={<b>提交（{preview.logs.length}）</b>}
+          size="small"
+          bordered
+          dataSource={preview.logs}
+          renderItem={(item: string) => <List.Item>{item}</List.Item>}
+        />
+      )}
+      {(preview.files || []).length > 0 && (
+        <List
+          style={{ marginTop: 16 }}
+          header={<b>变化的文件（{preview.files.length}）</b>}
+          size="small"
+          bordered
+          dataSource={preview.files}
+          renderItem={(item: any) => (
+            <List.Item>
+              <Tag color={statusColor[item.status]}>{item.status}</Tag>
+              {item.path}
+            </List.Item>
+          )}
+        />
+      )}
+    </Modal>
+  );
+};
+export default SyncPreview;
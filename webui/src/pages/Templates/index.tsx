@@ -2,6 +2,7 @@ import {Button, Card, Divider, Form, message, Modal} from 'antd';
 import {PageHeaderWrapper} from '@ant-design/pro-layout';
 import React, {Fragment, useRef, useState} from 'react';
 import ListForm from "./components/ListForm"
+import SyncPreview from "./components/SyncPreview"
 import {PlusOutlined} from '@ant-design/icons';
 import SearchTable, {SearchTableInstance} from '@/components/SearchTable';
 import {history} from "umi";
@@ -48,6 +49,8 @@ const handleUpdate = async (values) => {
 const TableList: React.FC<{}> = () => {
   const [createModalVisible, handleCreateModalVisible] = useState<boolean>(false);
   const [updateModalVisible, handleUpdateModalVisible] = useState<boolean>(false);
+  const [syncModalVisible, handleSyncModalVisible] = useState<boolean>(false);
+  const [syncPreview, setSyncPreview] = useState<any>({});
   const [initialValues, setInitialValues] = useState({});
   const [form] = Form.useForm();
   const actionRef = useRef<SearchTableInstance>();
@@ -56,6 +59,13 @@ const TableList: React.FC<{}> = () => {
       title: "模板名",
       dataIndex: "name",
       key: "name",
+    }, {
+      title: "来源",
+      dataIndex: "kind",
+      key: "kind",
+      render(val) {
+        return {git: "git仓库", dir: "本地目录", archive: "压缩包"}[val] || val
+      },
     }, {
       title: "项目地址",
       dataIndex: "gitRemotePath",
@@ -91,20 +101,16 @@ const TableList: React.FC<{}> = () => {
           <Divider type="vertical"/>
           <a
             onClick={() => {
-              Modal.confirm({
-                title: '第一次同步模板时间较长，请耐心等待？',
-                okText: '确认',
-                cancelText: '取消',
-                onOk: () => {
-                  api.TemplateSync(record).then((res) => {
-                    if (res.code !== 0) {
-                      message.error(res.msg);
-                      return false;
-                    }
-                    actionRef.current?.refresh();
-                    return true;
-                  });
-                },
+              const hide = message.loading('正在获取模板的更新');
+              api.TemplateFetch(record).then((res) => {
+                hide();
+                if (res.code !== 0) {
+                  message.error(res.msg);
+                  return false;
+                }
+                setSyncPreview({...res.data, gitRemotePath: record.gitRemotePath});
+                handleSyncModalVisible(true);
+                return true;
               });
             }}
           >
@@ -166,6 +172,30 @@ const TableList: React.FC<{}> = () => {
           request={(params) => api.TemplateList({...params})}
         />
       </Card>
+      <SyncPreview
+        modalVisible={syncModalVisible}
+        preview={syncPreview}
+        onSubmit={() => {
+          const hide = message.loading('正在更新模板，第一次同步模板时间较长，请耐心等待');
+          api.TemplateApply({
+            gitRemotePath: syncPreview.gitRemotePath,
+            remoteVersion: syncPreview.remoteVersion,
+          }).then((res) => {
+            hide();
+            if (res.code !== 0) {
+              message.error(res.msg);
+              return false;
+            }
+            message.success('更新成功');
+            handleSyncModalVisible(false);
+            actionRef.current?.refresh();
+            return true;
+          });
+        }}
+        onCancel={() => {
+          handleSyncModalVisible(false);
+        }}
+      />
       <ListForm
         formTitle={"创建"}
         onSubmit={async (value) => {
@@ -12,6 +12,7 @@ export default {
       method: "GET",
       params: {
         path: params.path,
+        prune: params.prune,
       },
     });
   },
@@ -23,6 +24,23 @@ export default {
       },
     });
   },
+  ProjectPreview: async (params: any) => {
+    return request(`/api/projects/preview`, {
+      method: "GET",
+      params: {
+        path: params.path,
+      },
+    });
+  },
+  ProjectExport: async (params: any) => {
+    return request(`/api/projects/export`, {
+      method: "GET",
+      params: {
+        path: params.path,
+        format: params.format,
+      },
+    });
+  },
   ProjectCreate: async (params: any) => {
     return request("/api/projects", {
       method: "POST",
@@ -41,12 +59,61 @@ export default {
       data: params,
     });
   },
+  ProjectVariables: async (params: any) => {
+    return request(`/api/projects/variables`, {
+      method: "GET",
+      params: {
+        path: params.path,
+        pathKey: params.pathKey,
+      },
+    });
+  },
+  ProjectUpdateVariables: async (params: any) => {
+    return request(`/api/projects/variables`, {
+      method: "PUT",
+      data: params,
+    });
+  },
+  ProjectUpdateTemplateRef: async (params: any) => {
+    return request(`/api/projects/template-ref`, {
+      method: "PUT",
+      data: params,
+    });
+  },
+  ProjectUpdateTemplates: async (params: any) => {
+    return request(`/api/projects/templates`, {
+      method: "PUT",
+      data: params,
+    });
+  },
+  ProjectUpgradePreview: async (params: any) => {
+    return request(`/api/projects/upgrade`, {
+      method: "GET",
+      params: {
+        path: params.path,
+        pathKey: params.pathKey,
+        templateRef: params.templateRef,
+      },
+    });
+  },
   ProjectDelete: async (params: any) => {
     return request(`/api/projects`, {
       method: "DELETE",
       data: params,
     });
   },
+  DSLFromDDL: async (params: any) => {
+    return request(`/api/dsl/ddl`, {
+      method: "POST",
+      data: params,
+    });
+  },
+  DSLFromOpenAPI: async (params: any) => {
+    return request(`/api/dsl/openapi`, {
+      method: "POST",
+      data: params,
+    });
+  },
   TemplateList: async (params: any) => {
     return request("/api/templates", {
       method: "GET",
@@ -59,6 +126,23 @@ export default {
       params,
     });
   },
+  TemplateTags: async (params: any) => {
+    return request("/api/templates/tags", {
+      method: "GET",
+      params: {
+        gitRemotePath: params.gitRemotePath,
+      },
+    });
+  },
+  TemplateModules: async (params: any) => {
+    return request("/api/templates/modules", {
+      method: "GET",
+      params: {
+        gitRemotePath: params.gitRemotePath,
+        proType: params.proType,
+      },
+    });
+  },
   TemplateCreate: async (params: any) => {
     return request("/api/templates", {
       method: "POST",
@@ -77,6 +161,18 @@ export default {
       data: params,
     });
   },
+  TemplateFetch: async (params: any) => {
+    return request(`/api/templates/fetch`, {
+      method: "PUT",
+      data: params,
+    });
+  },
+  TemplateApply: async (params: any) => {
+    return request(`/api/templates/apply`, {
+      method: "PUT",
+      data: params,
+    });
+  },
   TemplateDelete: async (params: any) => {
     return request(`/api/templates`, {
       method: "DELETE",